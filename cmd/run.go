@@ -92,7 +92,22 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		}
 
 		var err error
-		client, err = findora.NewClient(cfg.RpcURL, cfg.Params, cfg.SkipFindoraAdmin)
+		client, err = findora.NewClient(cfg.RpcURL, cfg.Params, &findora.ClientOptions{
+			SkipAdminCalls:          cfg.SkipFindoraAdmin,
+			EnableTraces:            cfg.EnableTraces,
+			Tracer:                  cfg.Tracer,
+			TracerTimeout:           cfg.TracerTimeout,
+			TokenRegistry:           cfg.TokenRegistry,
+			TokenCacheFile:          cfg.TokenCacheFile,
+			FeePolicy:               cfg.FeePolicy,
+			ReceiptBatchSize:        cfg.ReceiptBatchSize,
+			BlockCacheSize:          cfg.BlockCacheSize,
+			BlockCacheConfirmations: cfg.BlockCacheConfirmations,
+			PrefetchBlocks:          cfg.PrefetchBlocks,
+			PrefetchConcurrency:     cfg.PrefetchConcurrency,
+			TipPollInterval:         cfg.TipPollInterval,
+			WebSocketURL:            cfg.WsURL,
+		})
 		if err != nil {
 			return fmt.Errorf("%w: cannot initialize findora client", err)
 		}
@@ -18,10 +18,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	findora "github/findoranetwork/findora-rosetta/findora"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/findoranetwork/rosetta-sdk-go/types"
 )
@@ -86,23 +90,153 @@ const (
 	// by hosted node services. When not set, defaults to false.
 	SkipFindoraAdminEnv = "SKIP_FINDORA_ADMIN"
 
+	// EnableTracesEnv is an optional environment variable
+	// to fetch `debug` call traces for every transaction, so that
+	// internal (contract) transfers are reported as operations.
+	// When not set, defaults to false.
+	EnableTracesEnv = "ENABLE_TRACES"
+
+	// TracerEnv is an optional environment variable used to
+	// select the tracer used when traces are enabled. It can be
+	// "JS" (the call_tracer.js embedded in the binary) or "NATIVE"
+	// (findora's built-in callTracer). When not set, defaults to JS.
+	TracerEnv = "TRACER"
+
+	// TracerTimeoutEnv is an optional environment variable
+	// used to set the timeout of a single trace request
+	// (e.g. "120s"). When not set, defaults to 120s.
+	TracerTimeoutEnv = "TRACER_TIMEOUT"
+
+	// TokenRegistryEnv is an optional environment variable
+	// pointing to a JSON file listing the contract, symbol and
+	// decimals of the tokens known on each network.
+	TokenRegistryEnv = "TOKEN_REGISTRY"
+
+	// TokenCacheEnv is an optional environment variable
+	// used to set the file where the metadata of tokens
+	// that are not in the token registry is cached once
+	// discovered. When not set, defaults to
+	// DataDirectory/DefaultTokenCacheFile.
+	TokenCacheEnv = "TOKEN_CACHE"
+
+	// ReceiptBatchSizeEnv is an optional environment variable
+	// used to set the maximum number of receipts requested in a
+	// single batch when the node does not support
+	// eth_getBlockReceipts. When not set, defaults to
+	// findora.DefaultReceiptBatchSize.
+	ReceiptBatchSizeEnv = "RECEIPT_BATCH_SIZE"
+
+	// BlockCacheSizeEnv is an optional environment variable
+	// used to set the maximum number of parsed blocks cached
+	// in memory (0 disables the cache). When not set, defaults
+	// to DefaultBlockCacheSize.
+	BlockCacheSizeEnv = "BLOCK_CACHE_SIZE"
+
+	// BlockCacheConfirmationsEnv is an optional environment
+	// variable used to set the number of blocks a block must
+	// be below the highest known block before it is cached.
+	// When not set, defaults to DefaultBlockCacheConfirmations.
+	BlockCacheConfirmationsEnv = "BLOCK_CACHE_CONFIRMATIONS"
+
+	// PrefetchBlocksEnv is an optional environment variable
+	// used to set the number of blocks fetched ahead when
+	// blocks are requested sequentially (0 disables
+	// prefetching). When not set, defaults to
+	// DefaultPrefetchBlocks.
+	PrefetchBlocksEnv = "PREFETCH_BLOCKS"
+
+	// PrefetchConcurrencyEnv is an optional environment
+	// variable used to set the maximum number of blocks
+	// prefetched concurrently. When not set, defaults to
+	// DefaultPrefetchConcurrency.
+	PrefetchConcurrencyEnv = "PREFETCH_CONCURRENCY"
+
+	// TipPollIntervalEnv is an optional environment variable
+	// used to set the interval at which the head of the chain
+	// and the sync status of the node are refreshed in the
+	// background (e.g. "2s", "0" disables the tip tracker).
+	// When not set, defaults to DefaultTipPollInterval.
+	TipPollIntervalEnv = "TIP_POLL_INTERVAL"
+
+	// TreasuryAddressEnv is an optional environment variable
+	// used to set the address of the treasury receiving a share
+	// of the transaction tips (see TreasuryShareEnv).
+	TreasuryAddressEnv = "TREASURY_ADDRESS"
+
+	// TreasuryShareEnv is an optional environment variable
+	// used to set the share of the transaction tips, in basis
+	// points, paid to the treasury instead of the block proposer.
+	// It must be set together with TreasuryAddressEnv.
+	TreasuryShareEnv = "TREASURY_SHARE"
+
+	// WsEnv is an optional environment variable used to
+	// subscribe to the new heads of the chain on the
+	// WebSocket endpoint of the findora node instead of
+	// polling them.
+	WsEnv = "WSURL"
+
+	// DefaultBlockCacheSize is the default maximum
+	// number of parsed blocks cached in memory.
+	DefaultBlockCacheSize = 256
+
+	// DefaultBlockCacheConfirmations is the default number
+	// of confirmations of a block before it is cached.
+	DefaultBlockCacheConfirmations = 6
+
+	// DefaultPrefetchBlocks is the default number of
+	// blocks fetched ahead during sequential syncing.
+	// Prefetching loads the node, so it is disabled
+	// unless PREFETCH_BLOCKS is set.
+	DefaultPrefetchBlocks = 0
+
+	// DefaultPrefetchConcurrency is the default maximum
+	// number of blocks prefetched concurrently.
+	DefaultPrefetchConcurrency = 4
+
+	// DefaultTipPollInterval is the default interval
+	// at which the tip tracker refreshes the tip. The
+	// tip tracker polls the node in the background, so
+	// it is disabled unless TIP_POLL_INTERVAL is set.
+	DefaultTipPollInterval = time.Duration(0)
+
+	// MaxTreasuryShare is the maximum treasury share
+	// (all of the tip), in basis points.
+	MaxTreasuryShare = 10000
+
+	// DefaultTokenCacheFile is the default name of
+	// the discovered token metadata cache file.
+	DefaultTokenCacheFile = "token_cache.json"
+
 	// MiddlewareVersion is the version of findora-rosetta.
 	MiddlewareVersion = "0.0.4"
 )
 
 // Configuration determines how
 type Configuration struct {
-	Mode                   Mode
-	Network                *types.NetworkIdentifier
-	GenesisBlockIdentifier *types.BlockIdentifier
-	RpcURL                 string
-	RemoteRpc              bool
-	Port                   int
-	FindoraArguments       string
-	SkipFindoraAdmin       bool
+	Mode                    Mode
+	Network                 *types.NetworkIdentifier
+	GenesisBlockIdentifier  *types.BlockIdentifier
+	RpcURL                  string
+	RemoteRpc               bool
+	Port                    int
+	FindoraArguments        string
+	SkipFindoraAdmin        bool
+	EnableTraces            bool
+	Tracer                  string
+	TracerTimeout           string
+	TokenRegistry           *findora.TokenRegistry
+	TokenCacheFile          string
+	ReceiptBatchSize        int
+	BlockCacheSize          int
+	BlockCacheConfirmations int64
+	PrefetchBlocks          int64
+	PrefetchConcurrency     int64
+	TipPollInterval         time.Duration
+	WsURL                   string
 
 	// Block Reward Data
-	Params *params.ChainConfig
+	Params    *params.ChainConfig
+	FeePolicy findora.FeePolicy
 }
 
 // LoadConfiguration attempts to create a new Configuration
@@ -162,6 +296,35 @@ func LoadConfiguration() (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid network", networkValue)
 	}
 
+	config.FeePolicy = findora.DefaultFeePolicy
+
+	envTreasuryAddress := os.Getenv(TreasuryAddressEnv)
+	envTreasuryShare := os.Getenv(TreasuryShareEnv)
+	if len(envTreasuryAddress) > 0 || len(envTreasuryShare) > 0 {
+		if len(envTreasuryAddress) == 0 || len(envTreasuryShare) == 0 {
+			return nil, errors.New("TREASURY_ADDRESS and TREASURY_SHARE must be populated together")
+		}
+
+		address, ok := findora.ChecksumAddress(envTreasuryAddress)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a valid TREASURY_ADDRESS", envTreasuryAddress)
+		}
+
+		share, err := strconv.ParseUint(envTreasuryShare, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse TREASURY_SHARE %s", err, envTreasuryShare)
+		}
+		if share > MaxTreasuryShare {
+			return nil, fmt.Errorf("TREASURY_SHARE must be at most %d, got %d", MaxTreasuryShare, share)
+		}
+
+		config.FeePolicy = &findora.TreasuryFeePolicy{
+			Policy:  config.FeePolicy,
+			Address: common.HexToAddress(address),
+			Share:   share,
+		}
+	}
+
 	config.RpcURL = DefaultRpcURL
 	envRpcURL := os.Getenv(RpcEnv)
 	if len(envRpcURL) > 0 {
@@ -169,6 +332,8 @@ func LoadConfiguration() (*Configuration, error) {
 		config.RpcURL = envRpcURL
 	}
 
+	config.WsURL = os.Getenv(WsEnv)
+
 	config.SkipFindoraAdmin = false
 	envSkipFindoraAdmin := os.Getenv(SkipFindoraAdminEnv)
 	if len(envSkipFindoraAdmin) > 0 {
@@ -179,6 +344,134 @@ func LoadConfiguration() (*Configuration, error) {
 		config.SkipFindoraAdmin = val
 	}
 
+	envEnableTraces := os.Getenv(EnableTracesEnv)
+	if len(envEnableTraces) > 0 {
+		val, err := strconv.ParseBool(envEnableTraces)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse ENABLE_TRACES %s", err, envEnableTraces)
+		}
+		config.EnableTraces = val
+	}
+
+	tracerValue := os.Getenv(TracerEnv)
+	switch strings.ToUpper(tracerValue) {
+	case "":
+	case "JS":
+		config.Tracer = findora.JSTracer
+	case "NATIVE":
+		config.Tracer = findora.NativeTracer
+	default:
+		return nil, fmt.Errorf("%s is not a valid tracer", tracerValue)
+	}
+
+	envTracerTimeout := os.Getenv(TracerTimeoutEnv)
+	if len(envTracerTimeout) > 0 {
+		if _, err := time.ParseDuration(envTracerTimeout); err != nil {
+			return nil, fmt.Errorf("%w: unable to parse TRACER_TIMEOUT %s", err, envTracerTimeout)
+		}
+		config.TracerTimeout = envTracerTimeout
+	}
+
+	envTokenRegistry := os.Getenv(TokenRegistryEnv)
+	if len(envTokenRegistry) > 0 {
+		registry, err := findora.LoadTokenRegistry(envTokenRegistry, config.Network.Network)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load TOKEN_REGISTRY %s", err, envTokenRegistry)
+		}
+		config.TokenRegistry = registry
+	}
+
+	config.TokenCacheFile = path.Join(DataDirectory, DefaultTokenCacheFile)
+	envTokenCache := os.Getenv(TokenCacheEnv)
+	if len(envTokenCache) > 0 {
+		config.TokenCacheFile = envTokenCache
+	}
+
+	envReceiptBatchSize := os.Getenv(ReceiptBatchSizeEnv)
+	if len(envReceiptBatchSize) > 0 {
+		val, err := strconv.Atoi(envReceiptBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse RECEIPT_BATCH_SIZE %s", err, envReceiptBatchSize)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("RECEIPT_BATCH_SIZE must be positive, got %d", val)
+		}
+		config.ReceiptBatchSize = val
+	}
+
+	config.BlockCacheSize = DefaultBlockCacheSize
+	envBlockCacheSize := os.Getenv(BlockCacheSizeEnv)
+	if len(envBlockCacheSize) > 0 {
+		val, err := strconv.Atoi(envBlockCacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse BLOCK_CACHE_SIZE %s", err, envBlockCacheSize)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("BLOCK_CACHE_SIZE must not be negative, got %d", val)
+		}
+		config.BlockCacheSize = val
+	}
+
+	config.BlockCacheConfirmations = DefaultBlockCacheConfirmations
+	envBlockCacheConfirmations := os.Getenv(BlockCacheConfirmationsEnv)
+	if len(envBlockCacheConfirmations) > 0 {
+		val, err := strconv.ParseInt(envBlockCacheConfirmations, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse BLOCK_CACHE_CONFIRMATIONS %s",
+				err,
+				envBlockCacheConfirmations,
+			)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("BLOCK_CACHE_CONFIRMATIONS must not be negative, got %d", val)
+		}
+		config.BlockCacheConfirmations = val
+	}
+
+	config.PrefetchBlocks = DefaultPrefetchBlocks
+	envPrefetchBlocks := os.Getenv(PrefetchBlocksEnv)
+	if len(envPrefetchBlocks) > 0 {
+		val, err := strconv.ParseInt(envPrefetchBlocks, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse PREFETCH_BLOCKS %s", err, envPrefetchBlocks)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("PREFETCH_BLOCKS must not be negative, got %d", val)
+		}
+		config.PrefetchBlocks = val
+	}
+
+	config.PrefetchConcurrency = DefaultPrefetchConcurrency
+	envPrefetchConcurrency := os.Getenv(PrefetchConcurrencyEnv)
+	if len(envPrefetchConcurrency) > 0 {
+		val, err := strconv.ParseInt(envPrefetchConcurrency, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse PREFETCH_CONCURRENCY %s",
+				err,
+				envPrefetchConcurrency,
+			)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("PREFETCH_CONCURRENCY must be positive, got %d", val)
+		}
+		config.PrefetchConcurrency = val
+	}
+
+	config.TipPollInterval = DefaultTipPollInterval
+	envTipPollInterval := os.Getenv(TipPollIntervalEnv)
+	if len(envTipPollInterval) > 0 {
+		val, err := time.ParseDuration(envTipPollInterval)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse TIP_POLL_INTERVAL %s", err, envTipPollInterval)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("TIP_POLL_INTERVAL must not be negative, got %s", val)
+		}
+		config.TipPollInterval = val
+	}
+
 	portValue := os.Getenv(PortEnv)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
@@ -19,7 +19,9 @@ import (
 	findora "github/findoranetwork/findora-rosetta/findora"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/findoranetwork/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,6 +33,20 @@ func TestLoadConfiguration(t *testing.T) {
 		Port             string
 		Findora          string
 		SkipFindoraAdmin string
+		EnableTraces     string
+		Tracer           string
+		TracerTimeout    string
+		TokenRegistry    string
+		TokenCache       string
+		ReceiptBatchSize string
+		BlockCacheSize   string
+		BlockCacheConfs  string
+		PrefetchBlocks   string
+		PrefetchConc     string
+		TipPollInterval  string
+		TreasuryAddress  string
+		TreasuryShare    string
+		Ws               string
 
 		cfg *Configuration
 		err error
@@ -58,12 +74,19 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    findora.MainnetNetwork,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.MainnetChainConfig,
-				GenesisBlockIdentifier: findora.MainnetGenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 DefaultRpcURL,
-				FindoraArguments:       findora.MainnetCommandArguments,
-				SkipFindoraAdmin:       false,
+				Params:                  findora.MainnetChainConfig,
+				FeePolicy:               findora.DefaultFeePolicy,
+				GenesisBlockIdentifier:  findora.MainnetGenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/data/token_cache.json",
+				BlockCacheSize:          DefaultBlockCacheSize,
+				BlockCacheConfirmations: DefaultBlockCacheConfirmations,
+				PrefetchBlocks:          DefaultPrefetchBlocks,
+				PrefetchConcurrency:     DefaultPrefetchConcurrency,
+				TipPollInterval:         DefaultTipPollInterval,
+				RpcURL:                  DefaultRpcURL,
+				FindoraArguments:        findora.MainnetCommandArguments,
+				SkipFindoraAdmin:        false,
 			},
 		},
 		"all set (mainnet) + findora": {
@@ -78,30 +101,50 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    findora.MainnetNetwork,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.MainnetChainConfig,
-				GenesisBlockIdentifier: findora.MainnetGenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 "http://blah",
-				RemoteRpc:              true,
-				FindoraArguments:       findora.MainnetCommandArguments,
-				SkipFindoraAdmin:       true,
+				Params:                  findora.MainnetChainConfig,
+				FeePolicy:               findora.DefaultFeePolicy,
+				GenesisBlockIdentifier:  findora.MainnetGenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/data/token_cache.json",
+				BlockCacheSize:          DefaultBlockCacheSize,
+				BlockCacheConfirmations: DefaultBlockCacheConfirmations,
+				PrefetchBlocks:          DefaultPrefetchBlocks,
+				PrefetchConcurrency:     DefaultPrefetchConcurrency,
+				TipPollInterval:         DefaultTipPollInterval,
+				RpcURL:                  "http://blah",
+				RemoteRpc:               true,
+				FindoraArguments:        findora.MainnetCommandArguments,
+				SkipFindoraAdmin:        true,
 			},
 		},
 		"all set (anvil)": {
-			Mode:    string(Online),
-			Network: Anvil,
-			Port:    "1000",
+			Mode:          string(Online),
+			Network:       Anvil,
+			Port:          "1000",
+			EnableTraces:  "TRUE",
+			Tracer:        "native",
+			TracerTimeout: "30s",
 			cfg: &Configuration{
 				Mode: Online,
 				Network: &types.NetworkIdentifier{
 					Network:    findora.AnvilNetwork,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.AnvilChainConfig,
-				GenesisBlockIdentifier: findora.AnvilGenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 DefaultRpcURL,
-				FindoraArguments:       findora.AnvilCommandArguments,
+				Params:                  findora.AnvilChainConfig,
+				FeePolicy:               findora.DefaultFeePolicy,
+				GenesisBlockIdentifier:  findora.AnvilGenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/data/token_cache.json",
+				BlockCacheSize:          DefaultBlockCacheSize,
+				BlockCacheConfirmations: DefaultBlockCacheConfirmations,
+				PrefetchBlocks:          DefaultPrefetchBlocks,
+				PrefetchConcurrency:     DefaultPrefetchConcurrency,
+				TipPollInterval:         DefaultTipPollInterval,
+				RpcURL:                  DefaultRpcURL,
+				FindoraArguments:        findora.AnvilCommandArguments,
+				EnableTraces:            true,
+				Tracer:                  findora.NativeTracer,
+				TracerTimeout:           "30s",
 			},
 		},
 		"all set (testnet)": {
@@ -115,12 +158,19 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    findora.AnvilNetwork,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.AnvilChainConfig,
-				GenesisBlockIdentifier: findora.AnvilGenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 DefaultRpcURL,
-				FindoraArguments:       findora.AnvilCommandArguments,
-				SkipFindoraAdmin:       true,
+				Params:                  findora.AnvilChainConfig,
+				FeePolicy:               findora.DefaultFeePolicy,
+				GenesisBlockIdentifier:  findora.AnvilGenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/data/token_cache.json",
+				BlockCacheSize:          DefaultBlockCacheSize,
+				BlockCacheConfirmations: DefaultBlockCacheConfirmations,
+				PrefetchBlocks:          DefaultPrefetchBlocks,
+				PrefetchConcurrency:     DefaultPrefetchConcurrency,
+				TipPollInterval:         DefaultTipPollInterval,
+				RpcURL:                  DefaultRpcURL,
+				FindoraArguments:        findora.AnvilCommandArguments,
+				SkipFindoraAdmin:        true,
 			},
 		},
 		"all set (qa02)": {
@@ -128,18 +178,42 @@ func TestLoadConfiguration(t *testing.T) {
 			Network:          Qa02,
 			Port:             "1000",
 			SkipFindoraAdmin: "TRUE",
+			TokenCache:       "/tmp/tokens.json",
+			ReceiptBatchSize: "50",
+			BlockCacheSize:   "0",
+			BlockCacheConfs:  "20",
+			PrefetchBlocks:   "16",
+			PrefetchConc:     "2",
+			TipPollInterval:  "5s",
+			TreasuryAddress:  "0x687422eea2cb73b5d3e242ba5456b782919afc85",
+			TreasuryShare:    "2500",
+			Ws:               "ws://127.0.0.1:8546",
 			cfg: &Configuration{
 				Mode: Online,
 				Network: &types.NetworkIdentifier{
 					Network:    findora.Qa02Network,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.Qa02ChainConfig,
-				GenesisBlockIdentifier: findora.Qa02GenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 DefaultRpcURL,
-				FindoraArguments:       findora.Qa02CommandArguments,
-				SkipFindoraAdmin:       true,
+				Params:                  findora.Qa02ChainConfig,
+				GenesisBlockIdentifier:  findora.Qa02GenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/tmp/tokens.json",
+				ReceiptBatchSize:        50,
+				BlockCacheSize:          0,
+				BlockCacheConfirmations: 20,
+				PrefetchBlocks:          16,
+				PrefetchConcurrency:     2,
+				TipPollInterval:         5 * time.Second,
+				WsURL:                   "ws://127.0.0.1:8546",
+				RpcURL:                  DefaultRpcURL,
+				FindoraArguments:        findora.Qa02CommandArguments,
+				SkipFindoraAdmin:        true,
+
+				FeePolicy: &findora.TreasuryFeePolicy{
+					Policy:  findora.DefaultFeePolicy,
+					Address: common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85"),
+					Share:   2500,
+				},
 			},
 		},
 		"all set (private blockchain)": {
@@ -152,11 +226,18 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    findora.PrinetNetwork,
 					Blockchain: findora.Blockchain,
 				},
-				Params:                 findora.PrinetPChainConfig,
-				GenesisBlockIdentifier: findora.PrinetGenesisBlockIdentifier,
-				Port:                   1000,
-				RpcURL:                 DefaultRpcURL,
-				FindoraArguments:       findora.PrinetCommandArguments,
+				Params:                  findora.PrinetPChainConfig,
+				FeePolicy:               findora.DefaultFeePolicy,
+				GenesisBlockIdentifier:  findora.PrinetGenesisBlockIdentifier,
+				Port:                    1000,
+				TokenCacheFile:          "/data/token_cache.json",
+				BlockCacheSize:          DefaultBlockCacheSize,
+				BlockCacheConfirmations: DefaultBlockCacheConfirmations,
+				PrefetchBlocks:          DefaultPrefetchBlocks,
+				PrefetchConcurrency:     DefaultPrefetchConcurrency,
+				TipPollInterval:         DefaultTipPollInterval,
+				RpcURL:                  DefaultRpcURL,
+				FindoraArguments:        findora.PrinetCommandArguments,
 			},
 		},
 		"invalid mode": {
@@ -177,6 +258,134 @@ func TestLoadConfiguration(t *testing.T) {
 			Port:    "bad port",
 			err:     errors.New("unable to parse port bad port"),
 		},
+		"invalid enable traces": {
+			Mode:         string(Online),
+			Network:      Anvil,
+			Port:         "1000",
+			EnableTraces: "bad",
+			err:          errors.New("unable to parse ENABLE_TRACES bad"),
+		},
+		"invalid token registry": {
+			Mode:          string(Online),
+			Network:       Anvil,
+			Port:          "1000",
+			TokenRegistry: "testdata/missing.json",
+			err:           errors.New("unable to load TOKEN_REGISTRY testdata/missing.json"),
+		},
+		"invalid tracer": {
+			Mode:    string(Online),
+			Network: Anvil,
+			Port:    "1000",
+			Tracer:  "bad tracer",
+			err:     errors.New("bad tracer is not a valid tracer"),
+		},
+		"invalid tracer timeout": {
+			Mode:          string(Online),
+			Network:       Anvil,
+			Port:          "1000",
+			TracerTimeout: "bad",
+			err:           errors.New("unable to parse TRACER_TIMEOUT bad"),
+		},
+		"invalid receipt batch size": {
+			Mode:             string(Online),
+			Network:          Anvil,
+			Port:             "1000",
+			ReceiptBatchSize: "bad",
+			err:              errors.New("unable to parse RECEIPT_BATCH_SIZE bad"),
+		},
+		"non-positive receipt batch size": {
+			Mode:             string(Online),
+			Network:          Anvil,
+			Port:             "1000",
+			ReceiptBatchSize: "0",
+			err:              errors.New("RECEIPT_BATCH_SIZE must be positive, got 0"),
+		},
+		"invalid block cache size": {
+			Mode:           string(Online),
+			Network:        Anvil,
+			Port:           "1000",
+			BlockCacheSize: "bad",
+			err:            errors.New("unable to parse BLOCK_CACHE_SIZE bad"),
+		},
+		"negative block cache size": {
+			Mode:           string(Online),
+			Network:        Anvil,
+			Port:           "1000",
+			BlockCacheSize: "-1",
+			err:            errors.New("BLOCK_CACHE_SIZE must not be negative, got -1"),
+		},
+		"invalid block cache confirmations": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			BlockCacheConfs: "bad",
+			err:             errors.New("unable to parse BLOCK_CACHE_CONFIRMATIONS bad"),
+		},
+		"negative block cache confirmations": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			BlockCacheConfs: "-1",
+			err:             errors.New("BLOCK_CACHE_CONFIRMATIONS must not be negative, got -1"),
+		},
+		"invalid prefetch blocks": {
+			Mode:           string(Online),
+			Network:        Anvil,
+			Port:           "1000",
+			PrefetchBlocks: "bad",
+			err:            errors.New("unable to parse PREFETCH_BLOCKS bad"),
+		},
+		"negative prefetch blocks": {
+			Mode:           string(Online),
+			Network:        Anvil,
+			Port:           "1000",
+			PrefetchBlocks: "-1",
+			err:            errors.New("PREFETCH_BLOCKS must not be negative, got -1"),
+		},
+		"non-positive prefetch concurrency": {
+			Mode:         string(Online),
+			Network:      Anvil,
+			Port:         "1000",
+			PrefetchConc: "0",
+			err:          errors.New("PREFETCH_CONCURRENCY must be positive, got 0"),
+		},
+		"invalid tip poll interval": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			TipPollInterval: "bad",
+			err:             errors.New("unable to parse TIP_POLL_INTERVAL bad"),
+		},
+		"negative tip poll interval": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			TipPollInterval: "-2s",
+			err:             errors.New("TIP_POLL_INTERVAL must not be negative, got -2s"),
+		},
+		"treasury share without address": {
+			Mode:          string(Online),
+			Network:       Anvil,
+			Port:          "1000",
+			TreasuryShare: "100",
+			err:           errors.New("TREASURY_ADDRESS and TREASURY_SHARE must be populated together"),
+		},
+		"invalid treasury address": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			TreasuryAddress: "bad",
+			TreasuryShare:   "100",
+			err:             errors.New("bad is not a valid TREASURY_ADDRESS"),
+		},
+		"invalid treasury share": {
+			Mode:            string(Online),
+			Network:         Anvil,
+			Port:            "1000",
+			TreasuryAddress: "0x687422eea2cb73b5d3e242ba5456b782919afc85",
+			TreasuryShare:   "10001",
+			err:             errors.New("TREASURY_SHARE must be at most 10000, got 10001"),
+		},
 	}
 
 	for name, test := range tests {
@@ -186,6 +395,20 @@ func TestLoadConfiguration(t *testing.T) {
 			os.Setenv(PortEnv, test.Port)
 			os.Setenv(RpcEnv, test.Findora)
 			os.Setenv(SkipFindoraAdminEnv, test.SkipFindoraAdmin)
+			os.Setenv(EnableTracesEnv, test.EnableTraces)
+			os.Setenv(TracerEnv, test.Tracer)
+			os.Setenv(TracerTimeoutEnv, test.TracerTimeout)
+			os.Setenv(TokenRegistryEnv, test.TokenRegistry)
+			os.Setenv(TokenCacheEnv, test.TokenCache)
+			os.Setenv(ReceiptBatchSizeEnv, test.ReceiptBatchSize)
+			os.Setenv(BlockCacheSizeEnv, test.BlockCacheSize)
+			os.Setenv(BlockCacheConfirmationsEnv, test.BlockCacheConfs)
+			os.Setenv(PrefetchBlocksEnv, test.PrefetchBlocks)
+			os.Setenv(PrefetchConcurrencyEnv, test.PrefetchConc)
+			os.Setenv(TipPollIntervalEnv, test.TipPollInterval)
+			os.Setenv(TreasuryAddressEnv, test.TreasuryAddress)
+			os.Setenv(TreasuryShareEnv, test.TreasuryShare)
+			os.Setenv(WsEnv, test.Ws)
 
 			cfg, err := LoadConfiguration()
 			if test.err != nil {
@@ -0,0 +1,194 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// maxAccountBalancesAddresses is the maximum number of
+	// addresses of a single AccountBalancesMethod call.
+	maxAccountBalancesAddresses = 100000
+
+	// accountBatchSize is the maximum number of eth_getBalance and
+	// eth_getTransactionCount requests sent in a single batch.
+	accountBatchSize = 200
+
+	// maxAccountBatchConcurrency is the maximum number of
+	// account batches sent concurrently.
+	maxAccountBatchConcurrency = int64(4) // nolint:gomnd
+)
+
+// AccountBalancesInput is the input to the call
+// method AccountBalancesMethod. The latest block is
+// used when neither Index nor Hash are set.
+type AccountBalancesInput struct {
+	Addresses []string `json:"addresses"`
+	Index     *int64   `json:"index,omitempty"`
+	Hash      *string  `json:"hash,omitempty"`
+}
+
+// AccountBalance is the FRA balance and the
+// nonce of an account at a block.
+type AccountBalance struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	Nonce   int64  `json:"nonce"`
+}
+
+// AccountBalancesOutput is the output of the call
+// method AccountBalancesMethod.
+type AccountBalancesOutput struct {
+	BlockIdentifier *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+	Balances        []*AccountBalance             `json:"balances"`
+}
+
+// accountBalancesCall implements the call method AccountBalancesMethod.
+func (ec *Client) accountBalancesCall(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (map[string]interface{}, error) {
+	var input AccountBalancesInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if len(input.Addresses) == 0 {
+		return nil, fmt.Errorf("%w: addresses missing from params", ErrCallParametersInvalid)
+	}
+	if len(input.Addresses) > maxAccountBalancesAddresses {
+		return nil, fmt.Errorf(
+			"%w: at most %d addresses can be requested",
+			ErrCallParametersInvalid,
+			maxAccountBalancesAddresses,
+		)
+	}
+
+	addresses := make([]common.Address, len(input.Addresses))
+	for i, address := range input.Addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("%w: invalid address %s", ErrCallParametersInvalid, address)
+		}
+		addresses[i] = common.HexToAddress(address)
+	}
+
+	output, err := ec.accountBalances(
+		ctx,
+		addresses,
+		&RosettaTypes.PartialBlockIdentifier{Index: input.Index, Hash: input.Hash},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := RosettaTypes.MarshalMap(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	return resp, nil
+}
+
+// accountBalances returns the FRA balance and the nonce of addresses,
+// all read from the state of the same block with eth_getBalance and
+// eth_getTransactionCount batches of at most accountBatchSize requests,
+// sent concurrently. The block must remain canonical while they are
+// read.
+func (ec *Client) accountBalances(
+	ctx context.Context,
+	addresses []common.Address,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*AccountBalancesOutput, error) {
+	head, err := ec.blockHeader(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	blockHash := head.Hash()
+	blockQuery := toBlockHashArg(blockHash)
+
+	balances := make([]hexutil.Big, len(addresses))
+	nonces := make([]hexutil.Uint64, len(addresses))
+	reqs := make([]rpc.BatchElem, 0, 2*len(addresses))
+	for i, address := range addresses {
+		reqs = append(
+			reqs,
+			rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{address, blockQuery},
+				Result: &balances[i],
+			},
+			rpc.BatchElem{
+				Method: "eth_getTransactionCount",
+				Args:   []interface{}{address, blockQuery},
+				Result: &nonces[i],
+			},
+		)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	sem := semaphore.NewWeighted(maxAccountBatchConcurrency)
+	for start := 0; start < len(reqs); start += accountBatchSize {
+		end := start + accountBatchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		batch := reqs[start:end]
+		g.Go(func() error {
+			if err := sem.Acquire(gctx, 1); err != nil {
+				return err
+			}
+			defer sem.Release(1)
+
+			return ec.c.BatchCallContext(gctx, batch)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, checkCanonical(reqs[i].Error, blockHash)
+		}
+	}
+
+	output := &AccountBalancesOutput{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  blockHash.Hex(),
+			Index: head.Number.Int64(),
+		},
+		Balances: make([]*AccountBalance, len(addresses)),
+	}
+	for i, address := range addresses {
+		output.Balances[i] = &AccountBalance{
+			Address: MustChecksum(address.Hex()),
+			Balance: (*big.Int)(&balances[i]).String(),
+			Nonce:   int64(nonces[i]),
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCall_AccountBalances(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	header := mockBalanceHeader(t, mockJSONRPC)
+
+	// Every account has a balance and a nonce equal to its index
+	addresses := make([]interface{}, 150)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i))).Hex()
+	}
+
+	var batches int32
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			atomic.AddInt32(&batches, 1)
+
+			r := args.Get(1).([]rpc.BatchElem)
+			assert.LessOrEqual(t, len(r), accountBatchSize)
+			for _, req := range r {
+				assert.Equal(t, map[string]interface{}{
+					"blockHash":        header.Hash(),
+					"requireCanonical": true,
+				}, req.Args[1])
+
+				index := new(big.Int).SetBytes(req.Args[0].(common.Address).Bytes())
+				switch req.Method {
+				case "eth_getBalance":
+					*(req.Result.(*hexutil.Big)) = hexutil.Big(*index)
+				case "eth_getTransactionCount":
+					*(req.Result.(*hexutil.Uint64)) = hexutil.Uint64(index.Uint64())
+				default:
+					t.Fatalf("unexpected method %s", req.Method)
+				}
+			}
+		},
+	)
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: AccountBalancesMethod,
+		Parameters: map[string]interface{}{
+			"addresses": addresses,
+			"hash":      header.Hash().Hex(),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&batches))
+
+	var output AccountBalancesOutput
+	assert.NoError(t, RosettaTypes.UnmarshalMap(resp.Result, &output))
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  header.Hash().Hex(),
+		Index: header.Number.Int64(),
+	}, output.BlockIdentifier)
+	assert.Len(t, output.Balances, len(addresses))
+	for i, balance := range output.Balances {
+		assert.Equal(t, &AccountBalance{
+			Address: addresses[i].(string),
+			Balance: fmt.Sprint(i),
+			Nonce:   int64(i),
+		}, balance)
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestCall_AccountBalances_Orphaned(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	header := mockBalanceHeader(t, mockJSONRPC)
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			r[1].Error = errors.New("hash is not currently canonical")
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: AccountBalancesMethod,
+		Parameters: map[string]interface{}{
+			"addresses": []interface{}{testBalanceAccount},
+			"hash":      header.Hash().Hex(),
+		},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrBlockOrphaned))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestCall_AccountBalances_InvalidParameters(t *testing.T) {
+	c := &Client{c: &mocks.JSONRPC{}}
+
+	for _, params := range []map[string]interface{}{
+		{},
+		{"addresses": []interface{}{}},
+		{"addresses": []interface{}{"hello"}},
+		{"addresses": testBalanceAccount},
+		{"addresses": make([]interface{}, maxAccountBalancesAddresses+1)},
+	} {
+		resp, err := c.Call(context.Background(), &RosettaTypes.CallRequest{
+			Method:     AccountBalancesMethod,
+			Parameters: params,
+		})
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testBalanceAccount = "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"
+
+// mockBalanceHeader makes mockJSONRPC return the
+// basic header for eth_getBlockByHash.
+func mockBalanceHeader(t *testing.T, mockJSONRPC *mocks.JSONRPC) *types.Header {
+	file, err := ioutil.ReadFile("testdata/basic_header.json")
+	assert.NoError(t, err)
+
+	header := new(types.Header)
+	assert.NoError(t, header.UnmarshalJSON(file))
+
+	mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_getBlockByHash",
+		header.Hash().Hex(),
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(**types.Header) = header
+		},
+	).Once()
+
+	return header
+}
+
+func TestBalance_Pinned(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	header := mockBalanceHeader(t, mockJSONRPC)
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 3)
+			for _, req := range r {
+				assert.Equal(t, common.HexToAddress(testBalanceAccount), req.Args[0])
+				assert.Equal(t, map[string]interface{}{
+					"blockHash":        header.Hash(),
+					"requireCanonical": true,
+				}, req.Args[1])
+			}
+
+			assert.Equal(t, "eth_getCode", r[0].Method)
+			*(r[0].Result.(*hexutil.Bytes)) = hexutil.Bytes{0x60, 0x80}
+			assert.Equal(t, "eth_getBalance", r[1].Method)
+			*(r[1].Result.(*hexutil.Big)) = hexutil.Big(*big.NewInt(1000))
+			assert.Equal(t, "eth_getTransactionCount", r[2].Method)
+			*(r[2].Result.(*hexutil.Uint64)) = 7
+		},
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: testBalanceAccount},
+		&RosettaTypes.PartialBlockIdentifier{Hash: RosettaTypes.String(header.Hash().Hex())},
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  header.Hash().Hex(),
+			Index: header.Number.Int64(),
+		},
+		Balances: []*RosettaTypes.Amount{
+			{Value: "1000", Currency: Currency},
+		},
+		Metadata: map[string]interface{}{
+			"nonce": int64(7),
+			"code":  "0x6080",
+		},
+	}, resp)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBalance_Orphaned(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	header := mockBalanceHeader(t, mockJSONRPC)
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			// The block is reorganized after the code is read
+			*(r[0].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+			r[1].Error = errors.New("hash is not currently canonical")
+			r[2].Error = errors.New("hash is not currently canonical")
+		},
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: testBalanceAccount},
+		&RosettaTypes.PartialBlockIdentifier{Hash: RosettaTypes.String(header.Hash().Hex())},
+		nil,
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrBlockOrphaned))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBalance_TimestampWithBlock(t *testing.T) {
+	c := &Client{c: &mocks.JSONRPC{}}
+
+	resp, err := c.Balance(
+		context.Background(),
+		&RosettaTypes.AccountIdentifier{
+			Address:  testBalanceAccount,
+			Metadata: map[string]interface{}{"timestamp": float64(1420000)},
+		},
+		&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(42)},
+		nil,
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrInvalidBalanceOptions))
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+)
+
+// BlockCacheStats are the statistics of the parsed block cache.
+type BlockCacheStats struct {
+	Enabled  bool   `json:"enabled"`
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+}
+
+// blockCache is a bounded LRU cache of parsed blocks keyed by hash,
+// with an index to hash mapping. Only blocks at least confirmations
+// blocks below the highest block seen are cached, so that blocks
+// that may still be reorganized are never returned from the cache.
+//
+// A nil *blockCache is a disabled cache.
+type blockCache struct {
+	capacity      int
+	confirmations int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *RosettaTypes.Block, most recently used first
+	byHash  map[string]*list.Element
+	byIndex map[int64]string
+	tip     int64
+	hits    uint64
+	misses  uint64
+}
+
+// newBlockCache returns a blockCache holding at most capacity
+// blocks, or nil (disabled) if capacity is not positive.
+func newBlockCache(capacity int, confirmations int64) *blockCache {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return &blockCache{
+		capacity:      capacity,
+		confirmations: confirmations,
+		lru:           list.New(),
+		byHash:        map[string]*list.Element{},
+		byIndex:       map[int64]string{},
+		tip:           -1,
+	}
+}
+
+// normalizeHash returns the canonical representation of hash.
+func normalizeHash(hash string) string {
+	return common.HexToHash(hash).Hex()
+}
+
+// ObserveTip records index as a block known to exist, which is
+// used to decide if a block is confirmed enough to be cached.
+func (c *blockCache) ObserveTip(index int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index > c.tip {
+		c.tip = index
+	}
+}
+
+// Get returns the cached block with hash.
+func (c *blockCache) Get(hash string) (*RosettaTypes.Block, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.get(normalizeHash(hash))
+}
+
+// GetByIndex returns the cached block at index.
+func (c *blockCache) GetByIndex(index int64) (*RosettaTypes.Block, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash, ok := c.byIndex[index]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	return c.get(hash)
+}
+
+func (c *blockCache) get(hash string) (*RosettaTypes.Block, bool) {
+	elem, ok := c.byHash[hash]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*RosettaTypes.Block), true
+}
+
+// Contains returns true if the block at index is cached. Unlike
+// GetByIndex, it does not count as a hit or a miss.
+func (c *blockCache) Contains(index int64) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.byIndex[index]
+	return ok
+}
+
+// Confirmed returns true if the block at index is
+// confirmed enough to be cached.
+func (c *blockCache) Confirmed(index int64) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tip-index >= c.confirmations
+}
+
+// Add caches block if it is confirmed enough.
+func (c *blockCache) Add(block *RosettaTypes.Block) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := block.BlockIdentifier.Index
+	if index > c.tip {
+		c.tip = index
+	}
+	if c.tip-index < c.confirmations {
+		return
+	}
+
+	hash := normalizeHash(block.BlockIdentifier.Hash)
+	if elem, ok := c.byHash[hash]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.byHash[hash] = c.lru.PushFront(block)
+	c.byIndex[index] = hash
+
+	if c.lru.Len() > c.capacity {
+		oldest := c.lru.Remove(c.lru.Back()).(*RosettaTypes.Block)
+		oldestHash := normalizeHash(oldest.BlockIdentifier.Hash)
+		delete(c.byHash, oldestHash)
+		if c.byIndex[oldest.BlockIdentifier.Index] == oldestHash {
+			delete(c.byIndex, oldest.BlockIdentifier.Index)
+		}
+	}
+}
+
+// Stats returns the statistics of the cache.
+func (c *blockCache) Stats() *BlockCacheStats {
+	if c == nil {
+		return &BlockCacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &BlockCacheStats{
+		Enabled:  true,
+		Size:     c.lru.Len(),
+		Capacity: c.capacity,
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+}
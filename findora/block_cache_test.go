@@ -0,0 +1,120 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCachedBlock(index int64) *RosettaTypes.Block {
+	return &RosettaTypes.Block{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  common.BigToHash(big.NewInt(index + 1)).Hex(),
+			Index: index,
+		},
+	}
+}
+
+func TestBlockCache(t *testing.T) {
+	c := newBlockCache(2, 3)
+
+	// Blocks that are not confirmed are not cached
+	c.ObserveTip(10)
+	c.Add(testCachedBlock(8))
+	_, ok := c.GetByIndex(8)
+	assert.False(t, ok)
+
+	block5, block6, block7 := testCachedBlock(5), testCachedBlock(6), testCachedBlock(7)
+	c.Add(block5)
+	c.Add(block6)
+
+	block, ok := c.GetByIndex(5)
+	assert.True(t, ok)
+	assert.Equal(t, block5, block)
+
+	// Hashes are not case sensitive
+	block, ok = c.Get(strings.ToUpper(block6.BlockIdentifier.Hash[2:]))
+	assert.True(t, ok)
+	assert.Equal(t, block6, block)
+
+	// The least recently used block is evicted
+	_, _ = c.Get(block5.BlockIdentifier.Hash)
+	c.Add(block7)
+	_, ok = c.GetByIndex(6)
+	assert.False(t, ok)
+	_, ok = c.Get(block6.BlockIdentifier.Hash)
+	assert.False(t, ok)
+	_, ok = c.GetByIndex(7)
+	assert.True(t, ok)
+
+	assert.Equal(t, &BlockCacheStats{
+		Enabled:  true,
+		Size:     2,
+		Capacity: 2,
+		Hits:     4,
+		Misses:   3,
+	}, c.Stats())
+}
+
+func TestBlockCache_Disabled(t *testing.T) {
+	c := newBlockCache(0, 0)
+	assert.Nil(t, c)
+
+	c.ObserveTip(10)
+	c.Add(testCachedBlock(1))
+	_, ok := c.GetByIndex(1)
+	assert.False(t, ok)
+	assert.Equal(t, &BlockCacheStats{}, c.Stats())
+}
+
+func TestBlock_Cached(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{blockCache: newBlockCache(10, 0)}
+
+	cached := testCachedBlock(1)
+	c.blockCache.Add(cached)
+
+	// The node is not called for cached blocks
+	block, err := c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{
+		Index: RosettaTypes.Int64(1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cached, block)
+
+	block, err = c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{
+		Hash: RosettaTypes.String(cached.BlockIdentifier.Hash),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cached, block)
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: BlockCacheStatsMethod,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"enabled":  true,
+		"size":     1,
+		"capacity": 10,
+		"hits":     uint64(2),
+		"misses":   uint64(0),
+	}, resp.Result)
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testBlocks are the testdata blocks with full transactions.
+var testBlocks = []string{
+	"block_0.json",
+	"block_10991.json",
+	"block_10992.json",
+	"block_10994.json",
+	"block_13998626.json",
+	"block_239782.json",
+	"block_363366.json",
+	"block_363415.json",
+	"block_363753.json",
+	"block_468179.json",
+	"block_468194.json",
+}
+
+func loadTestBlock(t testing.TB, name string) []byte {
+	raw, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.NoError(t, err)
+
+	return raw
+}
+
+func TestRPCBlockHeader(t *testing.T) {
+	for _, name := range testBlocks {
+		t.Run(name, func(t *testing.T) {
+			raw := loadTestBlock(t, name)
+
+			var expected types.Header
+			assert.NoError(t, json.Unmarshal(raw, &expected))
+
+			var body rpcBlock
+			assert.NoError(t, json.Unmarshal(raw, &body))
+			head, err := body.header()
+			assert.NoError(t, err)
+
+			assert.Equal(t, &expected, head)
+			assert.Equal(t, body.Hash, head.Hash())
+		})
+	}
+
+	var body rpcBlock
+	assert.NoError(t, json.Unmarshal([]byte(`{"parentHash": "0x0000000000000000000000000000000000000000000000000000000000000000"}`), &body))
+	_, err := body.header()
+	assert.EqualError(t, err, "missing required field 'sha3Uncles' for Header")
+}
+
+func TestReceiptMetadata(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "tx_receipt_*.json"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			raw, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+
+			var receipt types.Receipt
+			assert.NoError(t, json.Unmarshal(raw, &receipt))
+
+			expected, err := receipt.MarshalJSON()
+			assert.NoError(t, err)
+			actual, err := json.Marshal(receiptMetadata(&receipt))
+			assert.NoError(t, err)
+
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func BenchmarkDecodeBlock(b *testing.B) {
+	raws := make([][]byte, len(testBlocks))
+	for i, name := range testBlocks {
+		raws[i] = loadTestBlock(b, name)
+	}
+
+	b.Run("single pass", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, raw := range raws {
+				var body rpcBlock
+				if err := json.Unmarshal(raw, &body); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := body.header(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	// Decoding the header and the body separately
+	// (as done before the single pass decoding)
+	b.Run("two passes", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, raw := range raws {
+				var head types.Header
+				if err := json.Unmarshal(raw, &head); err != nil {
+					b.Fatal(err)
+				}
+				var body rpcBlock
+				if err := json.Unmarshal(raw, &body); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkReceiptMetadata(b *testing.B) {
+	files, err := filepath.Glob(filepath.Join("testdata", "tx_receipt_*.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	receipts := make([]*types.Receipt, len(files))
+	for i, file := range files {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		receipts[i] = new(types.Receipt)
+		if err := json.Unmarshal(raw, receipts[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, receipt := range receipts {
+				_ = receiptMetadata(receipt)
+			}
+		}
+	})
+
+	// Marshaling the receipt and unmarshaling it into a map
+	// (as done before receiptMetadata)
+	b.Run("round trip", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, receipt := range receipts {
+				raw, err := receipt.MarshalJSON()
+				if err != nil {
+					b.Fatal(err)
+				}
+				var m map[string]interface{}
+				if err := json.Unmarshal(raw, &m); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkPopulateTransactions(b *testing.B) {
+	ctx := context.Background()
+	c := &Client{p: AnvilChainConfig, feePolicy: &FindoraFeePolicy{}}
+
+	from := common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85")
+	to := common.HexToAddress("0xc662a694fdaa5406a8ee2ca2e94890d58ab578d9")
+	loadedTxs := make([]*loadedTransaction, 5000) // nolint:gomnd
+	for i := range loadedTxs {
+		tx := types.NewTransaction(uint64(i), to, big.NewInt(1), 21000, big.NewInt(1), nil)
+		loadedTxs[i] = &loadedTransaction{
+			Transaction: tx,
+			From:        &from,
+			FeeAmount:   big.NewInt(21000),
+			FeeSplit:    c.feePolicy.Split(big.NewInt(21000), 21000, nil),
+			Miner:       to.Hex(),
+			Receipt: &types.Receipt{
+				Status:  types.ReceiptStatusSuccessful,
+				TxHash:  tx.Hash(),
+				GasUsed: 21000,
+			},
+		}
+	}
+
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			if _, err := c.populateTransactions(ctx, loadedTxs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, tx := range loadedTxs {
+				if _, err := c.populateTransaction(ctx, tx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
+)
+
+// blockFetchTimeout bounds a block fetch. It is not tied to the
+// request that triggered it, as concurrent requests and prefetches
+// share its result.
+const blockFetchTimeout = 2 * time.Minute
+
+// blockFetcher fetches and parses the block at index.
+type blockFetcher func(ctx context.Context, index int64) (*RosettaTypes.Block, error)
+
+// blockPrefetcher detects sequential block requests (N, then N+1) and
+// fetches the next blocks in the background so that they are already
+// in the block cache when they are requested. Requests for a block
+// that is being prefetched wait for the prefetch instead of fetching
+// the block again.
+type blockPrefetcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	fetch blockFetcher
+	cache *blockCache
+	depth int64
+	sem   *semaphore.Weighted
+	group singleflight.Group
+
+	mu       sync.Mutex
+	last     int64
+	inflight map[int64]struct{}
+}
+
+// newBlockPrefetcher returns a blockPrefetcher fetching up to depth
+// blocks ahead with at most concurrency fetches at a time, or nil if
+// prefetching is disabled (depth or concurrency is not positive, or
+// there is no block cache to prefetch blocks into).
+func newBlockPrefetcher(
+	fetch blockFetcher,
+	cache *blockCache,
+	depth int64,
+	concurrency int64,
+) *blockPrefetcher {
+	if cache == nil || depth <= 0 || concurrency <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &blockPrefetcher{
+		ctx:      ctx,
+		cancel:   cancel,
+		fetch:    fetch,
+		cache:    cache,
+		depth:    depth,
+		sem:      semaphore.NewWeighted(concurrency),
+		last:     -1,
+		inflight: map[int64]struct{}{},
+	}
+}
+
+// Fetch returns the block at index. If the blocks before index were
+// requested sequentially, the next blocks are prefetched. Concurrent
+// requests for index share a single fetch, which is not cancelled when
+// ctx is: ctx only bounds how long the caller waits.
+func (p *blockPrefetcher) Fetch(ctx context.Context, index int64) (*RosettaTypes.Block, error) {
+	p.observe(index)
+
+	ch := p.group.DoChan(strconv.FormatInt(index, 10), func() (interface{}, error) {
+		return p.fetchDetached(index)
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*RosettaTypes.Block), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchDetached fetches the block at index with a context that is
+// only cancelled by Close or after blockFetchTimeout.
+func (p *blockPrefetcher) fetchDetached(index int64) (*RosettaTypes.Block, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, blockFetchTimeout)
+	defer cancel()
+
+	return p.fetch(ctx, index)
+}
+
+// observe records a request for index and starts prefetching
+// the next blocks if the requests are sequential.
+func (p *blockPrefetcher) observe(index int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sequential := index == p.last+1
+	p.last = index
+	if !sequential {
+		return
+	}
+
+	for next := index + 1; next <= index+p.depth; next++ {
+		// Blocks that are not confirmed would not be cached
+		if !p.cache.Confirmed(next) {
+			break
+		}
+
+		if _, ok := p.inflight[next]; ok || p.cache.Contains(next) {
+			continue
+		}
+
+		p.inflight[next] = struct{}{}
+		go p.prefetch(next)
+	}
+}
+
+// prefetch fetches the block at index (which adds it
+// to the block cache). Errors are ignored: the block
+// is fetched again when it is requested.
+func (p *blockPrefetcher) prefetch(index int64) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.inflight, index)
+		p.mu.Unlock()
+	}()
+
+	if err := p.sem.Acquire(p.ctx, 1); err != nil {
+		return
+	}
+	defer p.sem.Release(1)
+
+	_, _, _ = p.group.Do(strconv.FormatInt(index, 10), func() (interface{}, error) {
+		return p.fetchDetached(index)
+	})
+}
+
+// Close stops all prefetches.
+func (p *blockPrefetcher) Close() {
+	if p == nil {
+		return
+	}
+
+	p.cancel()
+}
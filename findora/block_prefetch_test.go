@@ -0,0 +1,138 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testFetcher is a blockFetcher recording the blocks
+// fetched and adding them to a block cache.
+type testFetcher struct {
+	cache *blockCache
+
+	mu      sync.Mutex
+	fetched map[int64]int
+}
+
+func (f *testFetcher) fetch(ctx context.Context, index int64) (*RosettaTypes.Block, error) {
+	if block, ok := f.cache.GetByIndex(index); ok {
+		return block, nil
+	}
+
+	f.mu.Lock()
+	f.fetched[index]++
+	f.mu.Unlock()
+
+	block := testCachedBlock(index)
+	f.cache.Add(block)
+	return block, nil
+}
+
+func (f *testFetcher) count(index int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.fetched[index]
+}
+
+func TestBlockPrefetcher(t *testing.T) {
+	cache := newBlockCache(100, 2)
+	cache.ObserveTip(20)
+	f := &testFetcher{cache: cache, fetched: map[int64]int{}}
+	p := newBlockPrefetcher(f.fetch, cache, 3, 2)
+	defer p.Close()
+
+	ctx := context.Background()
+
+	// A single request is not sequential
+	block, err := p.Fetch(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, testCachedBlock(5), block)
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, cache.Contains(6))
+
+	// Sequential requests prefetch the next blocks
+	_, err = p.Fetch(ctx, 6)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return cache.Contains(7) && cache.Contains(8) && cache.Contains(9)
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, cache.Contains(10))
+
+	// Prefetched blocks are not fetched again
+	for index := int64(7); index <= 9; index++ {
+		_, err = p.Fetch(ctx, index)
+		assert.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return cache.Contains(12)
+	}, time.Second, 10*time.Millisecond)
+	for index := int64(5); index <= 12; index++ {
+		assert.Equal(t, 1, f.count(index), "block %d", index)
+	}
+
+	// Blocks that would not be cached are not prefetched
+	for index := int64(13); index <= 18; index++ {
+		_, err = p.Fetch(ctx, index)
+		assert.NoError(t, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, f.count(19))
+}
+
+func TestBlockPrefetcher_Canceled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetchErr := make(chan error, 1)
+	fetch := func(ctx context.Context, index int64) (*RosettaTypes.Block, error) {
+		close(started)
+		<-release
+		fetchErr <- ctx.Err()
+		return testCachedBlock(index), nil
+	}
+	p := newBlockPrefetcher(fetch, newBlockCache(10, 0), 1, 1)
+	defer p.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	callerErr := make(chan error)
+	go func() {
+		_, err := p.Fetch(canceled, 42)
+		callerErr <- err
+	}()
+
+	// Canceling the caller does not cancel the shared fetch
+	<-started
+	cancel()
+	assert.ErrorIs(t, <-callerErr, context.Canceled)
+
+	close(release)
+	assert.NoError(t, <-fetchErr)
+}
+
+func TestBlockPrefetcher_Disabled(t *testing.T) {
+	assert.Nil(t, newBlockPrefetcher(nil, nil, 10, 4))
+	assert.Nil(t, newBlockPrefetcher(nil, newBlockCache(10, 0), 0, 4))
+
+	// Closing a disabled prefetcher is a no-op
+	var p *blockPrefetcher
+	p.Close()
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+)
+
+// headerTimeCacheSize is the maximum number of
+// header timestamps cached by a headerTimeCache.
+const headerTimeCacheSize = 4096
+
+// headerTime is the hash and the timestamp
+// (in milliseconds) of a block header.
+type headerTime struct {
+	Index     int64
+	Hash      string
+	Timestamp int64
+}
+
+// headerTimeCache is a bounded LRU cache of the timestamps of
+// headers, keyed by index. Only headers with at least confirmations
+// blocks above them are cached, so that cached timestamps are not
+// changed by reorgs.
+//
+// A nil *headerTimeCache is a disabled cache.
+type headerTimeCache struct {
+	confirmations int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *headerTime, most recently used first
+	byIndex map[int64]*list.Element
+}
+
+// newHeaderTimeCache returns an empty headerTimeCache.
+func newHeaderTimeCache(confirmations int64) *headerTimeCache {
+	return &headerTimeCache{
+		confirmations: confirmations,
+		lru:           list.New(),
+		byIndex:       map[int64]*list.Element{},
+	}
+}
+
+// Get returns the cached timestamp of the header at index.
+func (c *headerTimeCache) Get(index int64) (*headerTime, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.byIndex[index]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*headerTime), true
+}
+
+// Add caches header if it is confirmed enough below tip.
+func (c *headerTimeCache) Add(header *headerTime, tip int64) {
+	if c == nil || tip-header.Index < c.confirmations {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.byIndex[header.Index]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.byIndex[header.Index] = c.lru.PushFront(header)
+	if c.lru.Len() > headerTimeCacheSize {
+		oldest := c.lru.Remove(c.lru.Back()).(*headerTime)
+		delete(c.byIndex, oldest.Index)
+	}
+}
+
+// headerTimeAt returns the timestamp of the header at index.
+func (ec *Client) headerTimeAt(ctx context.Context, index int64, tip int64) (*headerTime, error) {
+	if header, ok := ec.headerTimes.Get(index); ok {
+		return header, nil
+	}
+
+	head, err := ec.blockHeaderByNumber(ctx, big.NewInt(index))
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get header %d", err, index)
+	}
+
+	header := &headerTime{
+		Index:     index,
+		Hash:      head.Hash().Hex(),
+		Timestamp: convertTime(head.Time),
+	}
+	ec.headerTimes.Add(header, tip)
+
+	return header, nil
+}
+
+// blockByTimestamp returns the last block produced at or before
+// timestamp (in milliseconds, like block timestamps) with a binary
+// search over block headers. The timestamp must not be before the
+// genesis block nor at or after the latest block (the last block
+// produced before it may not be known yet).
+func (ec *Client) blockByTimestamp(ctx context.Context, timestamp int64) (*headerTime, error) {
+	latest, err := ec.blockHeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get latest header", err)
+	}
+	tip := latest.Number.Int64()
+
+	if timestamp >= convertTime(latest.Time) {
+		return nil, fmt.Errorf(
+			"%w: %d is not before the latest block (%d)",
+			ErrInvalidTimestamp,
+			timestamp,
+			convertTime(latest.Time),
+		)
+	}
+
+	genesis, err := ec.headerTimeAt(ctx, GenesisBlockIndex, tip)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp < genesis.Timestamp {
+		return nil, fmt.Errorf(
+			"%w: %d is before the genesis block (%d)",
+			ErrInvalidTimestamp,
+			timestamp,
+			genesis.Timestamp,
+		)
+	}
+
+	// The block at low is produced at or before timestamp
+	// and the block at high is produced after timestamp
+	low, high := genesis, tip
+	for high-low.Index > 1 {
+		mid, err := ec.headerTimeAt(ctx, low.Index+(high-low.Index)/2, tip)
+		if err != nil {
+			return nil, err
+		}
+
+		if mid.Timestamp <= timestamp {
+			low = mid
+		} else {
+			high = mid.Index
+		}
+	}
+
+	return low, nil
+}
+
+// BlockByTimestampInput is the input to the call
+// method BlockByTimestampMethod.
+type BlockByTimestampInput struct {
+	Timestamp *int64 `json:"timestamp"`
+}
+
+// blockByTimestampCall implements the call method BlockByTimestampMethod.
+func (ec *Client) blockByTimestampCall(
+	ctx context.Context,
+	parameters map[string]interface{},
+) (map[string]interface{}, error) {
+	var input BlockByTimestampInput
+	if err := RosettaTypes.UnmarshalMap(parameters, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if input.Timestamp == nil {
+		return nil, fmt.Errorf("%w: timestamp missing from params", ErrCallParametersInvalid)
+	}
+
+	header, err := ec.blockByTimestamp(ctx, *input.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"block_identifier": &RosettaTypes.BlockIdentifier{
+			Index: header.Index,
+			Hash:  header.Hash,
+		},
+		"timestamp": header.Timestamp,
+	}, nil
+}
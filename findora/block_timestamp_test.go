@@ -0,0 +1,181 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testTimestampChain returns the headers of a chain with tip blocks
+// above genesis, produced every 10 seconds from genesis at 1000s.
+func testTimestampChain(tip int64) []*types.Header {
+	headers := make([]*types.Header, tip+1)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Time:       uint64(1000 + 10*i),
+			Difficulty: big.NewInt(0),
+		}
+	}
+
+	return headers
+}
+
+// mockTimestampChain makes mockJSONRPC return headers for
+// eth_getBlockByNumber and counts the headers requested.
+func mockTimestampChain(mockJSONRPC *mocks.JSONRPC, headers []*types.Header) *int {
+	calls := 0
+	mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		mock.Anything,
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			calls++
+
+			header := headers[len(headers)-1]
+			if arg := args.Get(3).(string); arg != "latest" {
+				header = headers[hexutil.MustDecodeBig(arg).Int64()]
+			}
+			*args.Get(1).(**types.Header) = header
+		},
+	)
+
+	return &calls
+}
+
+func TestBlockByTimestamp(t *testing.T) {
+	headers := testTimestampChain(100)
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTimestampChain(mockJSONRPC, headers)
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		timestamp int64
+		index     int64
+	}{
+		"genesis":            {timestamp: 1000000, index: 0},
+		"after genesis":      {timestamp: 1009999, index: 0},
+		"exact":              {timestamp: 1420000, index: 42},
+		"between blocks":     {timestamp: 1425000, index: 42},
+		"before latest":      {timestamp: 1999999, index: 99},
+		"before second last": {timestamp: 1989999, index: 98},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header, err := c.blockByTimestamp(ctx, test.timestamp)
+			assert.NoError(t, err)
+			assert.Equal(t, &headerTime{
+				Index:     test.index,
+				Hash:      headers[test.index].Hash().Hex(),
+				Timestamp: convertTime(headers[test.index].Time),
+			}, header)
+		})
+	}
+}
+
+func TestBlockByTimestamp_Invalid(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTimestampChain(mockJSONRPC, testTimestampChain(100))
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	for _, timestamp := range []int64{999999, 2000000, 3000000} {
+		header, err := c.blockByTimestamp(ctx, timestamp)
+		assert.Nil(t, header)
+		assert.True(t, errors.Is(err, ErrInvalidTimestamp))
+	}
+}
+
+func TestBlockByTimestamp_Cache(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	calls := mockTimestampChain(mockJSONRPC, testTimestampChain(100))
+	c := &Client{
+		c:           mockJSONRPC,
+		headerTimes: newHeaderTimeCache(10),
+	}
+	ctx := context.Background()
+
+	// Headers within 10 blocks of the tip are not cached
+	for _, timestamp := range []int64{1420000, 1995000} {
+		_, err := c.blockByTimestamp(ctx, timestamp)
+		assert.NoError(t, err)
+
+		*calls = 0
+		_, err = c.blockByTimestamp(ctx, timestamp)
+		assert.NoError(t, err)
+		if timestamp == 1420000 {
+			assert.Equal(t, 1, *calls) // only the latest header
+		} else {
+			assert.Greater(t, *calls, 1)
+		}
+	}
+}
+
+func TestCall_BlockByTimestamp(t *testing.T) {
+	headers := testTimestampChain(100)
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTimestampChain(mockJSONRPC, headers)
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: BlockByTimestampMethod,
+		Parameters: map[string]interface{}{
+			"timestamp": float64(1425000),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"block_identifier": &RosettaTypes.BlockIdentifier{
+				Index: 42,
+				Hash:  headers[42].Hash().Hex(),
+			},
+			"timestamp": int64(1420000),
+		},
+	}, resp)
+
+	for _, params := range []map[string]interface{}{
+		{},
+		{"timestamp": "yesterday"},
+		{"timestamp": float64(1)},
+	} {
+		resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+			Method:     BlockByTimestampMethod,
+			Parameters: params,
+		})
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+	}
+}
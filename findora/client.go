@@ -22,23 +22,24 @@ import (
 	"log"
 	"math/big"
 	"net/http"
-	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/types"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
-
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 const (
@@ -47,33 +48,151 @@ const (
 	maxTraceConcurrency  = int64(16) // nolint:gomnd
 	semaphoreTraceWeight = int64(1)  // nolint:gomnd
 
+	// DefaultReceiptBatchSize is the default maximum number of
+	// eth_getTransactionReceipt requests sent in a single batch.
+	DefaultReceiptBatchSize = 100
+
+	// maxReceiptBatchConcurrency is the maximum number of
+	// receipt batches of a block sent concurrently.
+	maxReceiptBatchConcurrency = int64(8) // nolint:gomnd
+
+	// populateConcurrency is the maximum number of transactions
+	// of a block populated concurrently.
+	populateConcurrency = 16 // nolint:gomnd
+
+	// methodNotFoundCode is the JSON-RPC error code
+	// returned for unsupported methods.
+	methodNotFoundCode = -32601
+
+	// executionRevertedCode is the JSON-RPC error code
+	// returned for reverted calls with revert data.
+	executionRevertedCode = 3
+
+	// executionRevertedError is the error returned by
+	// the node for reverted calls.
+	executionRevertedError = "execution reverted"
+
+	// notCanonicalError is the error returned by the node
+	// for EIP-1898 block parameters requiring a canonical
+	// block when the block is not canonical.
+	notCanonicalError = "hash is not currently canonical"
+
 	// eip1559TxType is the EthTypes.Transaction.Type() value that indicates this transaction
 	// follows EIP-1559.
 	eip1559TxType = 2
 )
 
 // Client allows for querying a set of specific Findora endpoints in an
-// idempotent manner. Client relies on the eth_*, debug_*, admin_*, and txpool_*
-// methods and on the graphql endpoint.
+// idempotent manner. Client relies on the eth_*, debug_* and admin_* methods,
+// and on the txpool_* methods and the graphql endpoint when the node exposes them.
 //
 // Client borrows HEAVILY from https://github.com/ethereum/go-ethereum/tree/master/ethclient.
 type Client struct {
-	p *params.ChainConfig
-	// tc *tracers.TraceConfig
+	p  *params.ChainConfig
+	tc *tracers.TraceConfig // nil if traces are disabled
 
 	c JSONRPC
 
-	c2 *ethclient.Client
+	g GraphQL // nil if the node does not expose GraphQL
 
-	// g GraphQL
+	// graphQLBlocks is set if blocks are fetched with GraphQL.
+	graphQLBlocks bool
 
 	traceSemaphore *semaphore.Weighted
 
 	skipAdminCalls bool
+
+	tokens    *TokenRegistry
+	discovery *tokenDiscovery
+
+	feePolicy FeePolicy
+
+	receiptBatchSize int
+
+	blockCache  *blockCache      // nil if the block cache is disabled
+	prefetcher  *blockPrefetcher // nil if prefetching is disabled
+	headerTimes *headerTimeCache
+
+	tip *tipTracker // nil if the tip tracker is disabled
+
+	mempool   mempoolSource // nil to use txpool_content
+	submitted *trackedTransactions
+
+	// blockReceiptsUnsupported is set (atomically) once the node
+	// reports that eth_getBlockReceipts is not supported.
+	blockReceiptsUnsupported int32
+}
+
+// ClientOptions configures the optional behaviour of a Client.
+type ClientOptions struct {
+	// SkipAdminCalls skips the admin_* calls which are typically
+	// not supported by hosted node services.
+	SkipAdminCalls bool
+
+	// EnableTraces populates every transaction with its debug_* call trace
+	// so that internal (contract) value transfers are returned as operations.
+	EnableTraces bool
+
+	// Tracer is the tracer used when EnableTraces is set (JSTracer or
+	// NativeTracer). When empty, defaults to JSTracer.
+	Tracer string
+
+	// TracerTimeout is the timeout of a single trace request (e.g. "120s").
+	// When empty, defaults to 120s.
+	TracerTimeout string
+
+	// TokenRegistry lists the tokens known on the network. Their symbol
+	// and decimals are used in token currencies and their balances can
+	// be queried with /account/balance.
+	TokenRegistry *TokenRegistry
+
+	// TokenCacheFile is the file where the metadata of tokens that are
+	// not in the TokenRegistry is cached once discovered. When empty,
+	// discovered tokens are only cached in memory.
+	TokenCacheFile string
+
+	// FeePolicy decides how transaction fees are split between burning,
+	// the block proposer and a treasury. When nil, defaults to
+	// DefaultFeePolicy.
+	FeePolicy FeePolicy
+
+	// ReceiptBatchSize is the maximum number of eth_getTransactionReceipt
+	// requests sent in a single batch when eth_getBlockReceipts is not
+	// supported. When 0, defaults to DefaultReceiptBatchSize.
+	ReceiptBatchSize int
+
+	// BlockCacheSize is the maximum number of parsed blocks cached in
+	// memory. When 0, parsed blocks are not cached.
+	BlockCacheSize int
+
+	// BlockCacheConfirmations is the number of blocks a block must be
+	// below the highest known block before it is cached.
+	BlockCacheConfirmations int64
+
+	// PrefetchBlocks is the number of blocks fetched ahead in the
+	// background when blocks are requested sequentially. Prefetched
+	// blocks are stored in the block cache, so prefetching is disabled
+	// when 0 or when the block cache is disabled.
+	PrefetchBlocks int64
+
+	// PrefetchConcurrency is the maximum number of blocks prefetched
+	// concurrently.
+	PrefetchConcurrency int64
+
+	// TipPollInterval is the interval at which the head of the chain
+	// and the sync status of the node are refreshed in the background,
+	// so that Status does not query the node. When 0, the tip is not
+	// tracked.
+	TipPollInterval time.Duration
+
+	// WebSocketURL is the WebSocket endpoint of the node. When set, new
+	// heads are received from a newHeads subscription instead of being
+	// polled.
+	WebSocketURL string
 }
 
 // NewClient creates a Client that from the provided url and params.
-func NewClient(url string, params *params.ChainConfig, skipAdminCalls bool) (*Client, error) {
+func NewClient(url string, params *params.ChainConfig, opts *ClientOptions) (*Client, error) {
 	c, err := rpc.DialHTTPWithClient(url, &http.Client{
 		Timeout: findoraHTTPTimeout,
 	})
@@ -82,33 +201,79 @@ func NewClient(url string, params *params.ChainConfig, skipAdminCalls bool) (*Cl
 		return nil, fmt.Errorf("%w: unable to dial node", err)
 	}
 
-	c2, err := ethclient.Dial(url)
+	var tc *tracers.TraceConfig
+	if opts.EnableTraces {
+		tc, err = loadTraceConfig(opts.Tracer, opts.TracerTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load trace config", err)
+		}
+	}
+
+	graphQLClient, err := newGraphQLClient(url)
 	if err != nil {
-		defer c2.Close()
-		return nil, fmt.Errorf("%w: cannot initialize ethclient client", err)
+		return nil, fmt.Errorf("%w: unable to create GraphQL client", err)
 	}
+	g, graphQLBlocks := probeGraphQL(graphQLClient)
 
-	// tc, err := loadTraceConfig()
-	// if err != nil {
-	// 	return nil, fmt.Errorf("%w: unable to load trace config", err)
-	// }
+	rpcClient := newCoalescingRPC(c)
 
-	// g, err := newGraphQLClient(url)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("%w: unable to create GraphQL client", err)
-	// }
+	discovery, err := newTokenDiscovery(rpcClient, opts.TokenCacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create token discovery", err)
+	}
+
+	feePolicy := opts.FeePolicy
+	if feePolicy == nil {
+		feePolicy = DefaultFeePolicy
+	}
+
+	client := &Client{
+		p:                params,
+		tc:               tc,
+		c:                rpcClient,
+		g:                g,
+		graphQLBlocks:    graphQLBlocks,
+		traceSemaphore:   semaphore.NewWeighted(maxTraceConcurrency),
+		skipAdminCalls:   opts.SkipAdminCalls,
+		tokens:           opts.TokenRegistry,
+		discovery:        discovery,
+		feePolicy:        feePolicy,
+		receiptBatchSize: opts.ReceiptBatchSize,
+		blockCache:       newBlockCache(opts.BlockCacheSize, opts.BlockCacheConfirmations),
+		headerTimes:      newHeaderTimeCache(opts.BlockCacheConfirmations),
+		submitted:        newTrackedTransactions(),
+	}
+	client.prefetcher = newBlockPrefetcher(
+		client.blockByIndex,
+		client.blockCache,
+		opts.PrefetchBlocks,
+		opts.PrefetchConcurrency,
+	)
+
+	var subscribe headSubscriber
+	if len(opts.WebSocketURL) > 0 {
+		subscribe = wsHeadSubscriber(opts.WebSocketURL)
+	}
+	client.tip = newTipTracker(client, opts.TipPollInterval, subscribe)
+	client.mempool = detectMempoolSource(rpcClient, opts.WebSocketURL, client.submitted)
 
-	// return &Client{params, tc, c, g, semaphore.NewWeighted(maxTraceConcurrency), skipAdminCalls}, nil
-	return &Client{params, c, c2, semaphore.NewWeighted(maxTraceConcurrency), skipAdminCalls}, nil
+	return client, nil
 }
 
-// Close shuts down the RPC client connection.
+// Close stops the background work of the client
+// and shuts down the RPC client connection.
 func (ec *Client) Close() {
+	ec.tip.Close()
+	ec.prefetcher.Close()
+	if ec.mempool != nil {
+		ec.mempool.Close()
+	}
 	ec.c.Close()
 }
 
 // Status returns findora status information
-// for determining node healthiness.
+// for determining node healthiness. When the
+// tip is tracked, it is returned from memory.
 func (ec *Client) Status(ctx context.Context) (
 	*RosettaTypes.BlockIdentifier,
 	int64,
@@ -116,14 +281,19 @@ func (ec *Client) Status(ctx context.Context) (
 	[]*RosettaTypes.Peer,
 	error,
 ) {
-	header, err := ec.blockHeaderByNumber(ctx, nil)
-	if err != nil {
-		return nil, -1, nil, nil, err
-	}
+	header, progress, ok := ec.tip.Tip()
+	if !ok {
+		var err error
+		header, err = ec.blockHeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, -1, nil, nil, err
+		}
+		ec.blockCache.ObserveTip(header.Number.Int64())
 
-	progress, err := ec.syncProgress(ctx)
-	if err != nil {
-		return nil, -1, nil, nil, err
+		progress, err = ec.syncProgress(ctx)
+		if err != nil {
+			return nil, -1, nil, nil, err
+		}
 	}
 
 	var syncStatus *RosettaTypes.SyncStatus
@@ -218,7 +388,39 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
+	if err := ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data)); err != nil {
+		return err
+	}
+
+	// Submitted transactions are listed in the mempool
+	// when the TxPool can't be listed
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	sender, _ := types.Sender(signer, tx)
+	ec.submitted.Add(tx.Hash(), sender)
+
+	return nil
+}
+
+// toBlockHashArg returns the EIP-1898 block parameter
+// requiring the block with hash to be canonical.
+func toBlockHashArg(hash common.Hash) map[string]interface{} {
+	return map[string]interface{}{
+		"blockHash":        hash,
+		"requireCanonical": true,
+	}
+}
+
+// checkCanonical returns ErrBlockOrphaned if err reports that
+// the block with hash is no longer canonical, or err otherwise.
+func checkCanonical(err error, hash common.Hash) error {
+	if strings.Contains(err.Error(), notCanonicalError) {
+		return fmt.Errorf("%w: block %s is no longer canonical", ErrBlockOrphaned, hash.Hex())
+	}
+
+	return err
 }
 
 func toBlockNumArg(number *big.Int) string {
@@ -285,22 +487,23 @@ func (ec *Client) Transaction(
 	var traces *Call
 	var rawTraces json.RawMessage
 	var addTraces bool
-	if header.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
+	if ec.tc != nil && header.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
 		addTraces = true
-		// traces, rawTraces, err = ec.getTransactionTraces(ctx, body.tx.Hash())
-		// if err != nil {
-		// 	return nil, fmt.Errorf("%w: could not get traces for %x", err, body.tx.Hash())
-		// }
+		traces, rawTraces, err = ec.getTransactionTraces(ctx, body.tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not get traces for %x", err, body.tx.Hash())
+		}
 	}
 
 	loadedTx := body.LoadedTransaction()
 	loadedTx.Transaction = body.tx
-	feeAmount, feeBurned, err := calculateGas(body.tx, receipt, *header)
+	feeAmount, err := calculateGas(body.tx, receipt, *header)
 	if err != nil {
 		return nil, err
 	}
 	loadedTx.FeeAmount = feeAmount
-	loadedTx.FeeBurned = feeBurned
+	loadedTx.FeeSplit = ec.feePolicy.Split(feeAmount, receipt.GasUsed, header.BaseFee)
+	loadedTx.BaseFee = header.BaseFee
 	loadedTx.Miner = MustChecksum(header.Coinbase.Hex())
 	loadedTx.Receipt = receipt
 
@@ -309,7 +512,7 @@ func (ec *Client) Transaction(
 		loadedTx.RawTrace = rawTraces
 	}
 
-	tx, err := ec.populateTransaction(loadedTx)
+	tx, err := ec.populateTransaction(ctx, loadedTx)
 	if err != nil {
 		return nil, fmt.Errorf("%w: cannot parse %s", err, loadedTx.Transaction.Hash().Hex())
 	}
@@ -325,22 +528,40 @@ func (ec *Client) Block(
 ) (*RosettaTypes.Block, error) {
 	if blockIdentifier != nil {
 		if blockIdentifier.Hash != nil {
+			if block, ok := ec.blockCache.Get(*blockIdentifier.Hash); ok {
+				return block, nil
+			}
+
 			return ec.getParsedBlock(ctx, "eth_getBlockByHash", *blockIdentifier.Hash, true)
 		}
 
 		if blockIdentifier.Index != nil {
-			return ec.getParsedBlock(
-				ctx,
-				"eth_getBlockByNumber",
-				toBlockNumArg(big.NewInt(*blockIdentifier.Index)),
-				true,
-			)
+			if ec.prefetcher != nil {
+				return ec.prefetcher.Fetch(ctx, *blockIdentifier.Index)
+			}
+
+			return ec.blockByIndex(ctx, *blockIdentifier.Index)
 		}
 	}
 
 	return ec.getParsedBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(nil), true)
 }
 
+// blockByIndex returns the block at index, from
+// the block cache if it is cached.
+func (ec *Client) blockByIndex(ctx context.Context, index int64) (*RosettaTypes.Block, error) {
+	if block, ok := ec.blockCache.GetByIndex(index); ok {
+		return block, nil
+	}
+
+	return ec.getParsedBlock(
+		ctx,
+		"eth_getBlockByNumber",
+		toBlockNumArg(big.NewInt(index)),
+		true,
+	)
+}
+
 // Header returns a block header from the current canonical chain. If number is
 // nil, the latest known header is returned.
 func (ec *Client) blockHeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
@@ -368,12 +589,89 @@ func (ec *Client) blockHeaderByHash(ctx context.Context, hash string) (*types.He
 	return head, err
 }
 
+// rpcBlock is a block returned by eth_getBlockBy*. The header fields
+// are decoded along with the body so that the block is only parsed
+// once (see header).
 type rpcBlock struct {
+	ParentHash  *common.Hash      `json:"parentHash"`
+	UncleHash   *common.Hash      `json:"sha3Uncles"`
+	Coinbase    *common.Address   `json:"miner"`
+	Root        *common.Hash      `json:"stateRoot"`
+	TxHash      *common.Hash      `json:"transactionsRoot"`
+	ReceiptHash *common.Hash      `json:"receiptsRoot"`
+	Bloom       *types.Bloom      `json:"logsBloom"`
+	Difficulty  *hexutil.Big      `json:"difficulty"`
+	Number      *hexutil.Big      `json:"number"`
+	GasLimit    *hexutil.Uint64   `json:"gasLimit"`
+	GasUsed     *hexutil.Uint64   `json:"gasUsed"`
+	Time        *hexutil.Uint64   `json:"timestamp"`
+	Extra       *hexutil.Bytes    `json:"extraData"`
+	MixDigest   *common.Hash      `json:"mixHash"`
+	Nonce       *types.BlockNonce `json:"nonce"`
+	BaseFee     *hexutil.Big      `json:"baseFeePerGas"`
+
 	Hash         common.Hash      `json:"hash"`
 	Transactions []rpcTransaction `json:"transactions"`
 	UncleHashes  []common.Hash    `json:"uncles"`
 }
 
+// header returns the *types.Header of the block. Like
+// types.Header.UnmarshalJSON, it fails if a required
+// field is missing.
+func (b *rpcBlock) header() (*types.Header, error) {
+	required := []struct {
+		field   string
+		missing bool
+	}{
+		{"parentHash", b.ParentHash == nil},
+		{"sha3Uncles", b.UncleHash == nil},
+		{"stateRoot", b.Root == nil},
+		{"transactionsRoot", b.TxHash == nil},
+		{"receiptsRoot", b.ReceiptHash == nil},
+		{"logsBloom", b.Bloom == nil},
+		{"difficulty", b.Difficulty == nil},
+		{"number", b.Number == nil},
+		{"gasLimit", b.GasLimit == nil},
+		{"gasUsed", b.GasUsed == nil},
+		{"timestamp", b.Time == nil},
+		{"extraData", b.Extra == nil},
+	}
+	for _, r := range required {
+		if r.missing {
+			return nil, fmt.Errorf("missing required field '%s' for Header", r.field)
+		}
+	}
+
+	head := &types.Header{
+		ParentHash:  *b.ParentHash,
+		UncleHash:   *b.UncleHash,
+		Root:        *b.Root,
+		TxHash:      *b.TxHash,
+		ReceiptHash: *b.ReceiptHash,
+		Bloom:       *b.Bloom,
+		Difficulty:  b.Difficulty.ToInt(),
+		Number:      b.Number.ToInt(),
+		GasLimit:    uint64(*b.GasLimit),
+		GasUsed:     uint64(*b.GasUsed),
+		Time:        uint64(*b.Time),
+		Extra:       *b.Extra,
+	}
+	if b.Coinbase != nil {
+		head.Coinbase = *b.Coinbase
+	}
+	if b.MixDigest != nil {
+		head.MixDigest = *b.MixDigest
+	}
+	if b.Nonce != nil {
+		head.Nonce = *b.Nonce
+	}
+	if b.BaseFee != nil {
+		head.BaseFee = b.BaseFee.ToInt()
+	}
+
+	return head, nil
+}
+
 func (ec *Client) getUncles(
 	ctx context.Context,
 	head *types.Header,
@@ -441,6 +739,10 @@ func (ec *Client) getBlock(
 	[]*loadedTransaction,
 	error,
 ) {
+	if ec.graphQLBlocks {
+		return ec.getGraphQLBlock(ctx, blockMethod, args...)
+	}
+
 	var raw json.RawMessage
 	err := ec.c.CallContext(ctx, &raw, blockMethod, args...)
 	if err != nil {
@@ -450,16 +752,16 @@ func (ec *Client) getBlock(
 	}
 
 	// Decode header and transactions
-	var head types.Header
 	var body rpcBlock
-	if err := json.Unmarshal(raw, &head); err != nil {
+	if err := json.Unmarshal(raw, &body); err != nil {
 		return nil, nil, err
 	}
-	if err := json.Unmarshal(raw, &body); err != nil {
+	head, err := body.header()
+	if err != nil {
 		return nil, nil, err
 	}
 
-	uncles, err := ec.getUncles(ctx, &head, &body)
+	uncles, err := ec.getUncles(ctx, head, &body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: unable to get uncles", err)
 	}
@@ -470,76 +772,95 @@ func (ec *Client) getBlock(
 		return nil, nil, fmt.Errorf("%w: could not get receipts for %x", err, body.Hash[:])
 	}
 
+	return ec.loadBlock(ctx, head, body.Hash, body.Transactions, uncles, receipts)
+}
+
+// loadBlock fetches the traces of the transactions of
+// the block (if enabled) and returns its loaded transactions.
+func (ec *Client) loadBlock(
+	ctx context.Context,
+	head *types.Header,
+	hash common.Hash,
+	transactions []rpcTransaction,
+	uncles []*types.Header,
+	receipts []*types.Receipt,
+) (
+	*types.Block,
+	[]*loadedTransaction,
+	error,
+) {
 	// Get block traces (not possible to make idempotent block transaction trace requests)
 	//
 	// We fetch traces last because we want to avoid limiting the number of other
 	// block-related data fetches we perform concurrently (we limit the number of
 	// concurrent traces that are computed to 16 to avoid overwhelming findora).
-	// var traces []*rpcCall
-	// var rawTraces []*rpcRawCall
-	// var addTraces bool
-	// if head.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
-	// 	addTraces = true
-	// 	traces, rawTraces, err = ec.getBlockTraces(ctx, body.Hash)
-	// 	if err != nil {
-	// 		return nil, nil, fmt.Errorf("%w: could not get traces for %x", err, body.Hash[:])
-	// 	}
-	// }
+	var traces []*rpcCall
+	var rawTraces []*rpcRawCall
+	var addTraces bool
+	if ec.tc != nil && head.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
+		addTraces = true
+		var err error
+		traces, rawTraces, err = ec.getBlockTraces(ctx, hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: could not get traces for %x", err, hash[:])
+		}
+		if len(traces) != len(transactions) {
+			return nil, nil, fmt.Errorf(
+				"expected %d traces for block %x but got %d",
+				len(transactions),
+				hash[:],
+				len(traces),
+			)
+		}
+	}
 
 	// Convert all txs to loaded txs
-	txs := make([]*types.Transaction, len(body.Transactions))
-	loadedTxs := make([]*loadedTransaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
+	txs := make([]*types.Transaction, len(transactions))
+	loadedTxs := make([]*loadedTransaction, len(transactions))
+	for i, tx := range transactions {
 		txs[i] = tx.tx
 		receipt := receipts[i]
-		if err != nil {
-			return nil, nil, fmt.Errorf("%w: failure getting effective gas price", err)
-		}
 		loadedTxs[i] = tx.LoadedTransaction()
 		loadedTxs[i].Transaction = txs[i]
 
-		feeAmount, feeBurned, err := calculateGas(txs[i], receipt, head)
+		feeAmount, err := calculateGas(txs[i], receipt, *head)
 		if err != nil {
 			return nil, nil, err
 		}
 		loadedTxs[i].FeeAmount = feeAmount
-		loadedTxs[i].FeeBurned = feeBurned
+		loadedTxs[i].FeeSplit = ec.feePolicy.Split(feeAmount, receipt.GasUsed, head.BaseFee)
+		loadedTxs[i].BaseFee = head.BaseFee
 		loadedTxs[i].Miner = MustChecksum(head.Coinbase.Hex())
 		loadedTxs[i].Receipt = receipt
 
 		// Continue if calls does not exist (occurs at genesis)
-		// if !addTraces {
-		// 	continue
-		// }
+		if !addTraces {
+			continue
+		}
 
-		//loadedTxs[i].Trace = traces[i].Result
-		//loadedTxs[i].RawTrace = rawTraces[i].Result
+		loadedTxs[i].Trace = traces[i].Result
+		loadedTxs[i].RawTrace = rawTraces[i].Result
 	}
 
-	return types.NewBlockWithHeader(&head).WithBody(txs, uncles), loadedTxs, nil
+	return types.NewBlockWithHeader(head).WithBody(txs, uncles), loadedTxs, nil
 }
 
+// calculateGas returns the fee paid by tx. How the fee is
+// distributed is decided by the FeePolicy of the network.
 func calculateGas(
 	tx *types.Transaction,
 	txReceipt *types.Receipt,
 	head types.Header,
 ) (
-	*big.Int, *big.Int, error,
+	*big.Int, error,
 ) {
 	gasUsed := new(big.Int).SetUint64(txReceipt.GasUsed)
 	gasPrice, err := effectiveGasPrice(tx, head.BaseFee)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: failure getting effective gas price", err)
-	}
-	feeAmount := new(big.Int).Mul(gasUsed, gasPrice)
-	var feeBurned *big.Int
-	if head.BaseFee != nil { // EIP-1559
-		feeBurned = new(big.Int).Mul(gasUsed, head.BaseFee)
-	} else { // Legacy, Findora EVM burns fee by default
-		feeBurned = new(big.Int).Mul(gasUsed, gasPrice)
+		return nil, fmt.Errorf("%w: failure getting effective gas price", err)
 	}
 
-	return feeAmount, feeBurned, nil
+	return new(big.Int).Mul(gasUsed, gasPrice), nil
 }
 
 // effectiveGasPrice returns the price of gas charged to this transaction to be included in the
@@ -557,70 +878,143 @@ func effectiveGasPrice(tx *EthTypes.Transaction, baseFee *big.Int) (*big.Int, er
 	return new(big.Int).Add(tip, baseFee), nil
 }
 
-// func (ec *Client) getTransactionTraces(
-// 	ctx context.Context,
-// 	transactionHash common.Hash,
-// ) (*Call, json.RawMessage, error) {
-// 	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
-// 		return nil, nil, err
-// 	}
-// 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
-
-// 	var call *Call
-// 	var raw json.RawMessage
-// 	err := ec.c.CallContext(ctx, &raw, "debug_traceTransaction", transactionHash, ec.tc)
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	// Decode *Call
-// 	if err := json.Unmarshal(raw, &call); err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	return call, raw, nil
-// }
-
-// func (ec *Client) getBlockTraces(
-// 	ctx context.Context,
-// 	blockHash common.Hash,
-// ) ([]*rpcCall, []*rpcRawCall, error) {
-// 	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
-// 		return nil, nil, err
-// 	}
-// 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
-
-// 	var calls []*rpcCall
-// 	var rawCalls []*rpcRawCall
-// 	var raw json.RawMessage
-// 	err := ec.c.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, ec.tc)
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	// Decode []*rpcCall
-// 	if err := json.Unmarshal(raw, &calls); err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	// Decode []*rpcRawCall
-// 	if err := json.Unmarshal(raw, &rawCalls); err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	return calls, rawCalls, nil
-// }
+func (ec *Client) getTransactionTraces(
+	ctx context.Context,
+	transactionHash common.Hash,
+) (*Call, json.RawMessage, error) {
+	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+		return nil, nil, err
+	}
+	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
+
+	var call *Call
+	var raw json.RawMessage
+	err := ec.c.CallContext(ctx, &raw, "debug_traceTransaction", transactionHash, ec.tc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode *Call
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return nil, nil, err
+	}
+
+	return call, raw, nil
+}
+
+func (ec *Client) getBlockTraces(
+	ctx context.Context,
+	blockHash common.Hash,
+) ([]*rpcCall, []*rpcRawCall, error) {
+	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+		return nil, nil, err
+	}
+	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
+
+	var calls []*rpcCall
+	var rawCalls []*rpcRawCall
+	var raw json.RawMessage
+	err := ec.c.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, ec.tc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode []*rpcCall
+	if err := json.Unmarshal(raw, &calls); err != nil {
+		return nil, nil, err
+	}
+
+	// Decode []*rpcRawCall
+	if err := json.Unmarshal(raw, &rawCalls); err != nil {
+		return nil, nil, err
+	}
+
+	return calls, rawCalls, nil
+}
 
 func (ec *Client) getBlockReceipts(
 	ctx context.Context,
 	blockHash common.Hash,
 	txs []rpcTransaction,
 ) ([]*types.Receipt, error) {
-	receipts := make([]*types.Receipt, len(txs))
 	if len(txs) == 0 {
-		return receipts, nil
+		return []*types.Receipt{}, nil
+	}
+
+	var receipts []*types.Receipt
+	if atomic.LoadInt32(&ec.blockReceiptsUnsupported) == 0 {
+		var err error
+		receipts, err = ec.getBlockReceiptsByHash(ctx, blockHash, txs)
+		if err != nil {
+			if isMethodNotFound(err) {
+				atomic.StoreInt32(&ec.blockReceiptsUnsupported, 1)
+			}
+
+			// Fall back to batches of eth_getTransactionReceipt
+			receipts = nil
+		}
+	}
+
+	if receipts == nil {
+		var err error
+		receipts, err = ec.getReceiptsInBatches(ctx, txs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range receipts {
+		if receipts[i].BlockHash != blockHash && receipts[i].Status != 0 {
+			return nil, fmt.Errorf(
+				"%w: expected block hash %s for transaction but got %s",
+				ErrBlockOrphaned,
+				blockHash.Hex(),
+				receipts[i].BlockHash.Hex(),
+			)
+		}
+	}
+
+	return receipts, nil
+}
+
+// getBlockReceiptsByHash fetches all receipts of a block with
+// a single eth_getBlockReceipts call.
+func (ec *Client) getBlockReceiptsByHash(
+	ctx context.Context,
+	blockHash common.Hash,
+	txs []rpcTransaction,
+) ([]*types.Receipt, error) {
+	var receipts []*types.Receipt
+	if err := ec.c.CallContext(ctx, &receipts, "eth_getBlockReceipts", blockHash); err != nil {
+		return nil, err
+	}
+
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("expected %d receipts but got %d", len(txs), len(receipts))
+	}
+
+	for i := range receipts {
+		if receipts[i] == nil || receipts[i].TxHash != txs[i].tx.Hash() {
+			return nil, fmt.Errorf("got unexpected receipt for %s", txs[i].tx.Hash().Hex())
+		}
+	}
+
+	return receipts, nil
+}
+
+// getReceiptsInBatches fetches the receipts of txs with
+// eth_getTransactionReceipt batches of at most receiptBatchSize
+// requests, sent concurrently.
+func (ec *Client) getReceiptsInBatches(
+	ctx context.Context,
+	txs []rpcTransaction,
+) ([]*types.Receipt, error) {
+	batchSize := ec.receiptBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultReceiptBatchSize
 	}
 
+	receipts := make([]*types.Receipt, len(txs))
 	reqs := make([]rpc.BatchElem, len(txs))
 	for i := range reqs {
 		reqs[i] = rpc.BatchElem{
@@ -629,12 +1023,28 @@ func (ec *Client) getBlockReceipts(
 			Result: &receipts[i],
 		}
 	}
-	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+
+	g, gctx := errgroup.WithContext(ctx)
+	sem := semaphore.NewWeighted(maxReceiptBatchConcurrency)
+	for start := 0; start < len(reqs); start += batchSize {
+		end := start + batchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		batch := reqs[start:end]
+		g.Go(func() error {
+			if err := sem.Acquire(gctx, 1); err != nil {
+				return err
+			}
+			defer sem.Release(1)
+
+			return ec.c.BatchCallContext(gctx, batch)
+		})
+	}
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
-	//for i := range reqs {
-	//	fmt.Println(i, "-------", receipts[i].TxHash.Hex(), "-------", blockHash, "-------", receipts[i].BlockHash.Hex())
-	//}
 
 	for i := range reqs {
 		if reqs[i].Error != nil {
@@ -643,20 +1053,30 @@ func (ec *Client) getBlockReceipts(
 		if receipts[i] == nil {
 			return nil, fmt.Errorf("got empty receipt for %x", txs[i].tx.Hash().Hex())
 		}
-
-		if receipts[i].BlockHash != blockHash && receipts[i].Status != 0 {
-			return nil, fmt.Errorf(
-				"%w: expected block hash %s for transaction but got %s",
-				ErrBlockOrphaned,
-				blockHash.Hex(),
-				receipts[i].BlockHash.Hex(),
-			)
-		}
 	}
 
 	return receipts, nil
 }
 
+// isMethodNotFound returns true if err is the error returned
+// by the node when a method is not supported.
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode
+}
+
+// isExecutionReverted returns true if err is the error returned
+// by the node when a call reverts.
+func isExecutionReverted(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+
+	return rpcErr.ErrorCode() == executionRevertedCode ||
+		strings.HasPrefix(rpcErr.Error(), executionRevertedError)
+}
+
 type rpcCall struct {
 	Result *Call `json:"result"`
 }
@@ -674,6 +1094,7 @@ type Call struct {
 	GasUsed      *big.Int       `json:"gasUsed"`
 	Revert       bool
 	ErrorMessage string  `json:"error"`
+	RevertReason string  `json:"revertReason"`
 	Calls        []*Call `json:"calls"`
 }
 
@@ -685,6 +1106,7 @@ type flatCall struct {
 	GasUsed      *big.Int       `json:"gasUsed"`
 	Revert       bool
 	ErrorMessage string `json:"error"`
+	RevertReason string `json:"revertReason"`
 }
 
 func (t *Call) flatten() *flatCall {
@@ -696,6 +1118,7 @@ func (t *Call) flatten() *flatCall {
 		GasUsed:      t.GasUsed,
 		Revert:       t.Revert,
 		ErrorMessage: t.ErrorMessage,
+		RevertReason: t.RevertReason,
 	}
 }
 
@@ -708,8 +1131,10 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		Value        *hexutil.Big   `json:"value"`
 		GasUsed      *hexutil.Big   `json:"gasUsed"`
 		Revert       bool
-		ErrorMessage string  `json:"error"`
-		Calls        []*Call `json:"calls"`
+		ErrorMessage string        `json:"error"`
+		RevertReason string        `json:"revertReason"`
+		Output       hexutil.Bytes `json:"output"`
+		Calls        []*Call       `json:"calls"`
 	}
 	var dec CustomTrace
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -735,6 +1160,13 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		t.Revert = true
 	}
 	t.ErrorMessage = dec.ErrorMessage
+
+	// Recent tracers decode the revert reason, older ones
+	// only return the revert data as the call output.
+	t.RevertReason = dec.RevertReason
+	if t.Revert && len(t.RevertReason) == 0 {
+		t.RevertReason, _ = decodeRevertReason(dec.Output)
+	}
 	t.Calls = dec.Calls
 	return nil
 }
@@ -752,6 +1184,7 @@ func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
 			// if child does not have one
 			if len(child.ErrorMessage) == 0 {
 				child.ErrorMessage = data.ErrorMessage
+				child.RevertReason = data.RevertReason
 			}
 		}
 
@@ -777,6 +1210,9 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 		if trace.Revert {
 			opStatus = FailureStatus
 			metadata["error"] = trace.ErrorMessage
+			if len(trace.RevertReason) > 0 {
+				metadata["revert_reason"] = trace.RevertReason
+			}
 		}
 
 		var zeroValue bool
@@ -951,7 +1387,8 @@ type loadedTransaction struct {
 	BlockNumber *string
 	BlockHash   *common.Hash
 	FeeAmount   *big.Int
-	FeeBurned   *big.Int // nil if no fees were burned
+	FeeSplit    *FeeSplit // nil if the whole fee is paid to the miner
+	BaseFee     *big.Int  // nil before EIP-1559
 	Miner       string
 	Status      bool
 
@@ -960,72 +1397,121 @@ type loadedTransaction struct {
 	Receipt  *types.Receipt
 }
 
+// feeOps returns the *RosettaTypes.Operation paying the fee of tx.
+// The part of the fee that is burned is debited from the sender by
+// a separate FEE_BURN operation, so that the operations of a block
+// add up to its supply change.
 func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
-	var minerEarnedAmount *big.Int
-	if tx.FeeBurned == nil {
-		minerEarnedAmount = tx.FeeAmount
-	} else {
-		minerEarnedAmount = new(big.Int).Sub(tx.FeeAmount, tx.FeeBurned)
+	split := tx.FeeSplit
+	if split == nil {
+		split = &FeeSplit{
+			Burned:   new(big.Int),
+			Tip:      tx.FeeAmount,
+			Treasury: new(big.Int),
+		}
 	}
 
-	// Transaction fee
-	ops := []*RosettaTypes.Operation{
-		{
+	var ops []*RosettaTypes.Operation
+	sender := MustChecksum(tx.From.String())
+
+	// Transaction fee (not burned)
+	paid := new(big.Int).Sub(tx.FeeAmount, split.Burned)
+	if paid.Sign() == 1 {
+		ops = append(ops, &RosettaTypes.Operation{
 			OperationIdentifier: &RosettaTypes.OperationIdentifier{
 				Index: 0,
 			},
 			Type:   FeeOpType,
 			Status: RosettaTypes.String(SuccessStatus),
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(tx.From.String()),
+				Address: sender,
 			},
 			Amount: &RosettaTypes.Amount{
-				Value:    new(big.Int).Neg(tx.FeeAmount).String(),
+				Value:    new(big.Int).Neg(paid).String(),
 				Currency: Currency,
 			},
-		},
+		})
+
+		recipients := []struct {
+			opType  string
+			address string
+			amount  *big.Int
+		}{
+			{FeeTipOpType, MustChecksum(tx.Miner), split.Tip},
+			{FeeTreasuryOpType, MustChecksum(split.TreasuryAddress.String()), split.Treasury},
+		}
+		for _, recipient := range recipients {
+			if recipient.amount.Sign() != 1 {
+				continue
+			}
+
+			ops = append(ops, &RosettaTypes.Operation{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: int64(len(ops)),
+				},
+				RelatedOperations: []*RosettaTypes.OperationIdentifier{
+					{
+						Index: 0,
+					},
+				},
+				Type:   recipient.opType,
+				Status: RosettaTypes.String(SuccessStatus),
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: recipient.address,
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    recipient.amount.String(),
+					Currency: Currency,
+				},
+			})
+		}
 	}
 
-	// Miner earned fee, if any
-	if minerEarnedAmount.Sign() == 1 {
-		earnedOp := &RosettaTypes.Operation{
+	// Burned fee, if any
+	if split.Burned.Sign() == 1 {
+		ops = append(ops, &RosettaTypes.Operation{
 			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: 1,
-			},
-			RelatedOperations: []*RosettaTypes.OperationIdentifier{
-				{
-					Index: 0,
-				},
+				Index: int64(len(ops)),
 			},
-			Type:   FeeOpType,
+			Type:   FeeBurnOpType,
 			Status: RosettaTypes.String(SuccessStatus),
 			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(tx.Miner),
+				Address: sender,
 			},
 			Amount: &RosettaTypes.Amount{
-				Value:    minerEarnedAmount.String(),
+				Value:    new(big.Int).Neg(split.Burned).String(),
 				Currency: Currency,
 			},
-		}
-		ops = append(ops, earnedOp)
+		})
 	}
 
 	return ops
 }
 
+// transferOps returns the *RosettaTypes.Operation moving the value of
+// tx. The operations of failed transactions have FailureStatus.
 func transferOps(tx *loadedTransaction, startIndex int) []*RosettaTypes.Operation {
 	value := tx.Transaction.Value()
-	var toAddr string = "0x"
-	if nil != tx.Transaction.To() {
+	opStatus := SuccessStatus
+	if tx.Receipt.Status != types.ReceiptStatusSuccessful {
+		opStatus = FailureStatus
+	}
+	opType := CallOpType
+	var toAddr string
+	if tx.Transaction.To() != nil {
 		toAddr = MustChecksum(tx.Transaction.To().String())
+	} else {
+		// Contract creations credit the deployed contract
+		opType = CreateOpType
+		toAddr = MustChecksum(tx.Receipt.ContractAddress.String())
 	}
 	ops := []*RosettaTypes.Operation{
 		{
 			OperationIdentifier: &RosettaTypes.OperationIdentifier{
 				Index: int64(startIndex),
 			},
-			Type:   CallOpType,
-			Status: RosettaTypes.String(SuccessStatus),
+			Type:   opType,
+			Status: RosettaTypes.String(opStatus),
 			Account: &RosettaTypes.AccountIdentifier{
 				Address: MustChecksum(tx.From.String()),
 			},
@@ -1044,8 +1530,8 @@ func transferOps(tx *loadedTransaction, startIndex int) []*RosettaTypes.Operatio
 					Index: int64(startIndex),
 				},
 			},
-			Type:   CallOpType,
-			Status: RosettaTypes.String(SuccessStatus),
+			Type:   opType,
+			Status: RosettaTypes.String(opStatus),
 			Account: &RosettaTypes.AccountIdentifier{
 				Address: toAddr,
 			},
@@ -1058,16 +1544,63 @@ func transferOps(tx *loadedTransaction, startIndex int) []*RosettaTypes.Operatio
 	return ops
 }
 
-// transactionReceipt returns the receipt of a transaction by transaction hash.
-// Note that the receipt is not available for pending transactions.
-func (ec *Client) transactionReceipt(
+// addContractCreationMetadata adds the address and the code hash of the
+// contract deployed by tx to the metadata of the CREATE operation
+// crediting it.
+func (ec *Client) addContractCreationMetadata(
 	ctx context.Context,
-	txHash common.Hash,
-) (*types.Receipt, error) {
-	var r *types.Receipt
-	err := ec.c.CallContext(ctx, &r, "eth_getTransactionReceipt", txHash)
-	if err == nil {
-		if r == nil {
+	tx *loadedTransaction,
+	ops []*RosettaTypes.Operation,
+) error {
+	contract := MustChecksum(tx.Receipt.ContractAddress.String())
+	for _, op := range ops {
+		if op.Type != CreateOpType || op.Account.Address != contract {
+			continue
+		}
+
+		// Skip debit operations of the contract (i.e. transfers
+		// made by its constructor)
+		if op.Amount != nil && strings.HasPrefix(op.Amount.Value, "-") {
+			continue
+		}
+
+		var code hexutil.Bytes
+		if err := ec.c.CallContext(
+			ctx,
+			&code,
+			"eth_getCode",
+			tx.Receipt.ContractAddress,
+			toBlockNumArg(tx.Receipt.BlockNumber),
+		); err != nil {
+			return fmt.Errorf("%w: could not get code of %s", err, contract)
+		}
+
+		// Copy the metadata as it may be shared with
+		// the debit operation
+		metadata := map[string]interface{}{}
+		for k, v := range op.Metadata {
+			metadata[k] = v
+		}
+		metadata[ContractAddressKey] = contract
+		metadata["code_hash"] = crypto.Keccak256Hash(code).Hex()
+		op.Metadata = metadata
+
+		return nil
+	}
+
+	return nil
+}
+
+// transactionReceipt returns the receipt of a transaction by transaction hash.
+// Note that the receipt is not available for pending transactions.
+func (ec *Client) transactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	var r *types.Receipt
+	err := ec.c.CallContext(ctx, &r, "eth_getTransactionReceipt", txHash)
+	if err == nil {
+		if r == nil {
 			return nil, ethereum.NotFound
 		}
 	}
@@ -1224,79 +1757,69 @@ func (ec *Client) getParsedBlock(
 		}
 	}
 
-	txs, err := ec.populateTransactions2(blockIdentifier, block, loadedTransactions)
+	txs, err := ec.populateTransactions(ctx, loadedTransactions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RosettaTypes.Block{
+	parsedBlock := &RosettaTypes.Block{
 		BlockIdentifier:       blockIdentifier,
 		ParentBlockIdentifier: parentBlockIdentifier,
 		Timestamp:             convertTime(block.Time()),
 		Transactions:          txs,
-	}, nil
+	}
+	ec.blockCache.Add(parsedBlock)
+
+	return parsedBlock, nil
 }
 
 func convertTime(time uint64) int64 {
 	return int64(time) * 1000
 }
 
+// populateTransactions populates loadedTransactions concurrently
+// (at most populateConcurrency at a time). The order of the returned
+// transactions is the order of loadedTransactions.
 func (ec *Client) populateTransactions(
-	blockIdentifier *RosettaTypes.BlockIdentifier,
-	block *EthTypes.Block,
+	ctx context.Context,
 	loadedTransactions []*loadedTransaction,
 ) ([]*RosettaTypes.Transaction, error) {
-	transactions := make(
-		[]*RosettaTypes.Transaction,
-		len(block.Transactions())+1, // include reward tx
-	)
-
-	// Compute reward transaction (block + uncle reward)
-	transactions[0] = ec.blockRewardTransaction(
-		blockIdentifier,
-		block.Coinbase().String(),
-		block.Uncles(),
-	)
+	transactions := make([]*RosettaTypes.Transaction, len(loadedTransactions))
 
+	g, gctx := errgroup.WithContext(ctx)
+	sem := semaphore.NewWeighted(int64(populateConcurrency))
 	for i, tx := range loadedTransactions {
-		transaction, err := ec.populateTransaction(
-			tx,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("%w: cannot parse %s", err, tx.Transaction.Hash().Hex())
+		i, tx := i, tx
+		if err := sem.Acquire(gctx, 1); err != nil {
+			break
 		}
 
-		transactions[i+1] = transaction
-	}
+		g.Go(func() error {
+			defer sem.Release(1)
 
-	return transactions, nil
-}
-
-func (ec *Client) populateTransactions2(
-	blockIdentifier *RosettaTypes.BlockIdentifier,
-	block *EthTypes.Block,
-	loadedTransactions []*loadedTransaction,
-) ([]*RosettaTypes.Transaction, error) {
-	transactions := make(
-		[]*RosettaTypes.Transaction,
-		len(block.Transactions()),
-	)
+			transaction, err := ec.populateTransaction(gctx, tx)
+			if err != nil {
+				return fmt.Errorf("%w: cannot parse %s", err, tx.Transaction.Hash().Hex())
+			}
 
-	for i, tx := range loadedTransactions {
-		transaction, err := ec.populateTransaction(
-			tx,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("%w: cannot parse %s", err, tx.Transaction.Hash().Hex())
-		}
+			transactions[i] = transaction
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-		transactions[i] = transaction
+	// Acquire also fails when ctx is cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	return transactions, nil
 }
 
 func (ec *Client) populateTransaction(
+	ctx context.Context,
 	tx *loadedTransaction,
 ) (*RosettaTypes.Transaction, error) {
 	var ops []*RosettaTypes.Operation
@@ -1305,140 +1828,135 @@ func (ec *Client) populateTransaction(
 	feeOps := feeOps(tx)
 	ops = append(ops, feeOps...)
 
-	// Compute transfer (successful tx ONLY) operations
-	if tx.Receipt.Status == 1 {
+	if tx.Trace != nil {
+		// Compute trace operations (the top-level call is the
+		// root of the trace, so transfer operations are not needed)
+		traces := flattenTraces(tx.Trace, []*flatCall{})
+
+		traceOps := traceOps(traces, len(ops))
+		ops = append(ops, traceOps...)
+	} else {
+		// Compute transfer operations
 		tsfOps := transferOps(tx, len(ops))
+
+		// Explain why failed transactions failed (without traces,
+		// the revert reason is only available by replaying them)
+		if tx.Receipt.Status != types.ReceiptStatusSuccessful {
+			metadata, err := ec.revertMetadata(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, op := range tsfOps {
+				op.Metadata = metadata
+			}
+		}
+
 		ops = append(ops, tsfOps...)
 	}
 
-	// Compute trace operations
-	// traces := flattenTraces(tx.Trace, []*flatCall{})
-
-	// traceOps := traceOps(traces, len(ops))
-	// ops = append(ops, traceOps...)
+	// Add the deployed contract to the CREATE operation
+	// crediting it
+	if tx.Transaction.To() == nil && tx.Receipt.Status == 1 {
+		if err := ec.addContractCreationMetadata(ctx, tx, ops); err != nil {
+			return nil, err
+		}
+	}
 
-	// Marshal receipt and trace data
-	// TODO: replace with marshalJSONMap (used in `services`)
-	receiptBytes, err := tx.Receipt.MarshalJSON()
+	// Compute token transfer operations
+	tokenOps, err := ec.tokenTransferOps(ctx, tx, len(ops))
 	if err != nil {
 		return nil, err
 	}
+	ops = append(ops, tokenOps...)
 
-	var receiptMap map[string]interface{}
-	if err := json.Unmarshal(receiptBytes, &receiptMap); err != nil {
+	metadata, err := transactionMetadata(tx)
+	if err != nil {
 		return nil, err
 	}
+	metadata["receipt"] = receiptMetadata(tx.Receipt)
 
-	var traceMap map[string]interface{}
-	// if err := json.Unmarshal(tx.RawTrace, &traceMap); err != nil {
-	// 	return nil, err
-	// }
+	// The raw trace is returned as is (it is
+	// only encoded by the server)
+	metadata["trace"] = nil
+	if len(tx.RawTrace) > 0 {
+		metadata["trace"] = tx.RawTrace
+	}
 
 	populatedTransaction := &RosettaTypes.Transaction{
 		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
 			Hash: tx.Transaction.Hash().Hex(),
 		},
 		Operations: ops,
-		Metadata: map[string]interface{}{
-			"gas_limit": hexutil.EncodeUint64(tx.Transaction.Gas()),
-			"gas_price": hexutil.EncodeBig(tx.Transaction.GasPrice()),
-			"receipt":   receiptMap,
-			"trace":     traceMap,
-		},
+		Metadata:   metadata,
 	}
 
 	return populatedTransaction, nil
 }
 
-// miningReward returns the mining reward
-// for a given block height.
-//
-// Source:
-// https://github.com/ethereum/go-ethereum/blob/master/consensus/ethash/consensus.go#L646-L653
-func (ec *Client) miningReward(
-	currentBlock *big.Int,
-) int64 {
-	blockReward := ethash.FrontierBlockReward.Int64()
-	if ec.p.IsByzantium(currentBlock) {
-		blockReward = ethash.ByzantiumBlockReward.Int64()
+// receiptMetadata returns the fields of receipt encoded
+// like types.Receipt.MarshalJSON, without a JSON round trip.
+func receiptMetadata(receipt *types.Receipt) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"root":              hexutil.Bytes(receipt.PostState),
+		"status":            hexutil.Uint64(receipt.Status),
+		"cumulativeGasUsed": hexutil.Uint64(receipt.CumulativeGasUsed),
+		"logsBloom":         receipt.Bloom,
+		"logs":              receipt.Logs,
+		"transactionHash":   receipt.TxHash,
+		"contractAddress":   receipt.ContractAddress,
+		"gasUsed":           hexutil.Uint64(receipt.GasUsed),
+		"blockHash":         receipt.BlockHash,
+		"transactionIndex":  hexutil.Uint(receipt.TransactionIndex),
+	}
+	if receipt.Type != types.LegacyTxType {
+		metadata["type"] = hexutil.Uint64(receipt.Type)
+	}
+	if receipt.BlockNumber != nil {
+		metadata["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+	}
+
+	return metadata
+}
+
+// transactionMetadata returns the metadata of a typed (legacy,
+// EIP-2930 or EIP-1559) transaction. gas_price is the gas fee
+// cap of EIP-1559 transactions, effective_gas_price is the
+// price actually paid.
+func transactionMetadata(tx *loadedTransaction) (map[string]interface{}, error) {
+	metadata := map[string]interface{}{
+		"type":      hexutil.EncodeUint64(uint64(tx.Transaction.Type())),
+		"nonce":     hexutil.EncodeUint64(tx.Transaction.Nonce()),
+		"input":     hexutil.Encode(tx.Transaction.Data()),
+		"gas_limit": hexutil.EncodeUint64(tx.Transaction.Gas()),
+		"gas_price": hexutil.EncodeBig(tx.Transaction.GasPrice()),
 	}
 
-	if ec.p.IsConstantinople(currentBlock) {
-		blockReward = ethash.ConstantinopleBlockReward.Int64()
+	// Legacy transactions signed without EIP-155 have no chain id
+	if tx.Transaction.Protected() {
+		metadata["chain_id"] = hexutil.EncodeBig(tx.Transaction.ChainId())
 	}
 
-	return blockReward
-}
+	if tx.Transaction.Type() != types.LegacyTxType {
+		metadata["access_list"] = tx.Transaction.AccessList()
+	}
 
-func (ec *Client) blockRewardTransaction(
-	blockIdentifier *RosettaTypes.BlockIdentifier,
-	miner string,
-	uncles []*EthTypes.Header,
-) *RosettaTypes.Transaction {
-	var ops []*RosettaTypes.Operation
-	miningReward := ec.miningReward(big.NewInt(blockIdentifier.Index))
-
-	// Calculate miner rewards
-	minerReward := miningReward
-	numUncles := len(uncles)
-	if len(uncles) > 0 {
-		reward := new(big.Float)
-		uncleReward := float64(numUncles) / UnclesRewardMultiplier
-		rewardFloat := reward.Mul(big.NewFloat(uncleReward), big.NewFloat(float64(miningReward)))
-		rewardInt, _ := rewardFloat.Int64()
-		minerReward += rewardInt
-	}
-
-	miningRewardOp := &RosettaTypes.Operation{
-		OperationIdentifier: &RosettaTypes.OperationIdentifier{
-			Index: 0,
-		},
-		Type:   MinerRewardOpType,
-		Status: RosettaTypes.String(SuccessStatus),
-		Account: &RosettaTypes.AccountIdentifier{
-			Address: MustChecksum(miner),
-		},
-		Amount: &RosettaTypes.Amount{
-			Value:    strconv.FormatInt(minerReward, 10),
-			Currency: Currency,
-		},
+	if tx.Transaction.Type() == eip1559TxType {
+		metadata["max_fee_per_gas"] = hexutil.EncodeBig(tx.Transaction.GasFeeCap())
+		metadata["max_priority_fee_per_gas"] = hexutil.EncodeBig(tx.Transaction.GasTipCap())
 	}
-	ops = append(ops, miningRewardOp)
-
-	// Calculate uncle rewards
-	for _, b := range uncles {
-		uncleMiner := b.Coinbase.String()
-		uncleBlock := b.Number.Int64()
-		uncleRewardBlock := new(
-			big.Int,
-		).Mul(
-			big.NewInt(uncleBlock+MaxUncleDepth-blockIdentifier.Index),
-			big.NewInt(miningReward/MaxUncleDepth),
-		)
 
-		uncleRewardOp := &RosettaTypes.Operation{
-			OperationIdentifier: &RosettaTypes.OperationIdentifier{
-				Index: int64(len(ops)),
-			},
-			Type:   UncleRewardOpType,
-			Status: RosettaTypes.String(SuccessStatus),
-			Account: &RosettaTypes.AccountIdentifier{
-				Address: MustChecksum(uncleMiner),
-			},
-			Amount: &RosettaTypes.Amount{
-				Value:    uncleRewardBlock.String(),
-				Currency: Currency,
-			},
+	// The effective gas price of EIP-1559 transactions
+	// can't be computed without the base fee
+	if tx.Transaction.Type() != eip1559TxType || tx.BaseFee != nil {
+		gasPrice, err := effectiveGasPrice(tx.Transaction, tx.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failure getting effective gas price", err)
 		}
-		ops = append(ops, uncleRewardOp)
+		metadata["effective_gas_price"] = hexutil.EncodeBig(gasPrice)
 	}
 
-	return &RosettaTypes.Transaction{
-		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
-			Hash: blockIdentifier.Hash,
-		},
-		Operations: ops,
-	}
+	return metadata, nil
 }
 
 type rpcProgress struct {
@@ -1476,150 +1994,231 @@ func (ec *Client) syncProgress(ctx context.Context) (*ethereum.SyncProgress, err
 	}, nil
 }
 
-type graphqlBalance struct {
-	Errors []struct {
-		Message string   `json:"message"`
-		Path    []string `json:"path"`
-	} `json:"errors"`
-	Data struct {
-		Block struct {
-			Hash    string `json:"hash"`
-			Number  int64  `json:"number"`
-			Account struct {
-				Balance string `json:"balance"`
-				Nonce   string `json:"transactionCount"`
-				Code    string `json:"code"`
-			} `json:"account"`
-		} `json:"block"`
-	} `json:"data"`
+// accountState is the state of an account at a block.
+type accountState struct {
+	Block   *RosettaTypes.BlockIdentifier
+	Balance *big.Int
+	Nonce   uint64
+	Code    string
+}
+
+// balanceOptions are the options of Balance, read from the
+// metadata of the *RosettaTypes.AccountIdentifier (the
+// /account/balance request does not have metadata).
+type balanceOptions struct {
+	// IncludeProof returns the eth_getProof proof of the
+	// account and whether it was verified.
+	IncludeProof bool `json:"include_proof"`
+
+	// Timestamp selects the last block produced at or before
+	// the timestamp (in milliseconds) instead of the requested
+	// block.
+	Timestamp *int64 `json:"timestamp"`
+
+	// IncludePending returns the PendingBalance of the account
+	// next to its confirmed balance at the latest block.
+	IncludePending bool `json:"include_pending"`
+}
+
+// parseBalanceOptions returns the balanceOptions in metadata.
+func parseBalanceOptions(metadata map[string]interface{}) (*balanceOptions, error) {
+	var opts balanceOptions
+	if err := RosettaTypes.UnmarshalMap(metadata, &opts); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBalanceOptions, err.Error())
+	}
+
+	return &opts, nil
 }
 
 // Balance returns the balance of a *RosettaTypes.AccountIdentifier
-// at a *RosettaTypes.PartialBlockIdentifier.
+// at a *RosettaTypes.PartialBlockIdentifier in each of the requested
+// currencies (FRA when none are requested).
+//
+// The balance, nonce and code of the account are read atomically
+// from the same block: with a single query when the node exposes
+// GraphQL, and with a batch pinned to the block hash otherwise.
 //
-// We must use graphql to get the balance atomically (the
-// rpc method for balance does not allow for querying
-// by block hash nor return the block hash where
-// the balance was fetched).
+// When the include_proof option is set in the account metadata, the
+// proof of the account state is returned in the response metadata
+// with the result of its verification against the state root. When
+// the include_pending option is set, the PendingBalance of the account
+// is returned in the "pending" metadata.
 func (ec *Client) Balance(
 	ctx context.Context,
 	account *RosettaTypes.AccountIdentifier,
 	block *RosettaTypes.PartialBlockIdentifier,
+	currencies []*RosettaTypes.Currency,
 ) (*RosettaTypes.AccountBalanceResponse, error) {
-	// // blockQuery := ""
-	// // if block != nil {
-	// // 	if block.Hash != nil {
-	// // 		blockQuery = fmt.Sprintf(`hash: "%s"`, *block.Hash)
-	// // 	}
-	// // 	if block.Hash == nil && block.Index != nil {
-	// // 		blockQuery = fmt.Sprintf("number: %d", *block.Index)
-	// // 	}
-	// // }
-
-	// var result []byte
-	// // result, err := ec.g.Query(ctx, fmt.Sprintf(`{
-	// // 		block(%s){
-	// // 			hash
-	// // 			number
-	// // 			account(address:"%s"){
-	// // 				balance
-	// // 				transactionCount
-	// // 				code
-	// // 			}
-	// // 		}
-	// // 	}`, blockQuery, account.Address))
-	// // if err != nil {
-	// // 	return nil, err
-	// // }
-
-	// var bal graphqlBalance
-	// if err := json.Unmarshal([]byte(result), &bal); err != nil {
-	// 	return nil, err
-	// }
+	opts, err := parseBalanceOptions(account.Metadata)
+	if err != nil {
+		return nil, err
+	}
 
-	// if len(bal.Errors) > 0 {
-	// 	return nil, errors.New(RosettaTypes.PrintStruct(bal.Errors))
-	// }
+	blockRequested := block != nil && (block.Hash != nil || block.Index != nil)
+	if opts.IncludePending && (blockRequested || opts.Timestamp != nil) {
+		return nil, fmt.Errorf(
+			"%w: include_pending can only be used with the latest block",
+			ErrInvalidBalanceOptions,
+		)
+	}
 
-	// balance, ok := new(big.Int).SetString(bal.Data.Block.Account.Balance[2:], 16)
-	// if !ok {
-	// 	return nil, fmt.Errorf(
-	// 		"could not extract account balance from %s",
-	// 		bal.Data.Block.Account.Balance,
-	// 	)
-	// }
-	// nonce, ok := new(big.Int).SetString(bal.Data.Block.Account.Nonce[2:], 16)
-	// if !ok {
-	// 	return nil, fmt.Errorf(
-	// 		"could not extract account nonce from %s",
-	// 		bal.Data.Block.Account.Nonce,
-	// 	)
-	// }
+	if opts.Timestamp != nil {
+		if blockRequested {
+			return nil, fmt.Errorf(
+				"%w: timestamp cannot be used with a block identifier",
+				ErrInvalidBalanceOptions,
+			)
+		}
 
-	// return &RosettaTypes.AccountBalanceResponse{
-	// 	Balances: []*RosettaTypes.Amount{
-	// 		{
-	// 			Value:    balance.String(),
-	// 			Currency: Currency,
-	// 		},
-	// 	},
-	// 	BlockIdentifier: &RosettaTypes.BlockIdentifier{
-	// 		Hash:  bal.Data.Block.Hash,
-	// 		Index: bal.Data.Block.Number,
-	// 	},
-	// 	Metadata: map[string]interface{}{
-	// 		"nonce": nonce.Int64(),
-	// 		"code":  bal.Data.Block.Account.Code,
-	// 	},
-	// }, nil
-
-	var err error
-	var eth_block *types.Block
-	if block != nil && block.Hash != nil {
-		eth_block, err = ec.c2.BlockByHash(ctx, common.HexToHash(*block.Hash))
-	} else if block != nil && block.Index != nil {
-		eth_block, err = ec.c2.BlockByNumber(ctx, big.NewInt(*block.Index))
+		header, err := ec.blockByTimestamp(ctx, *opts.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		block = &RosettaTypes.PartialBlockIdentifier{Hash: &header.Hash}
+	}
+
+	var state *accountState
+	if ec.g != nil {
+		state, err = ec.graphQLAccountState(ctx, account.Address, block)
 	} else {
-		eth_block, err = ec.c2.BlockByNumber(ctx, nil) // latest block
+		state, err = ec.accountState(ctx, common.HexToAddress(account.Address), block)
 	}
 	if err != nil {
 		return nil, err
 	}
-	blockNum := eth_block.Header().Number
+
+	blockHash := common.HexToHash(state.Block.Hash)
 	address := common.HexToAddress(account.Address)
 
-	code, err := ec.c2.CodeAt(ctx, address, blockNum)
-	if err != nil {
-		return nil, err
+	// Only FRA is returned when no currencies are requested
+	if len(currencies) == 0 {
+		currencies = []*RosettaTypes.Currency{Currency}
+	}
+
+	balances := make([]*RosettaTypes.Amount, len(currencies))
+	for i, currency := range currencies {
+		if IsNativeCurrency(currency) {
+			balances[i] = &RosettaTypes.Amount{
+				Value:    state.Balance.String(),
+				Currency: Currency,
+			}
+			continue
+		}
+
+		contract, err := ec.tokenContract(currency)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenBalance, err := ec.tokenBalance(ctx, contract, address, toBlockHashArg(blockHash))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: could not get %s balance",
+				checkCanonical(err, blockHash),
+				contract.Hex(),
+			)
+		}
+
+		tokenCurrency, err := ec.tokenCurrency(ctx, contract)
+		if err != nil {
+			return nil, err
+		}
+
+		balances[i] = &RosettaTypes.Amount{
+			Value:    tokenBalance.String(),
+			Currency: tokenCurrency,
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"nonce": int64(state.Nonce),
+		"code":  state.Code,
+	}
+	if opts.IncludeProof {
+		proof, err := ec.accountProof(ctx, address, state)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not get proof of %s", err, address.Hex())
+		}
+
+		metadata["proof"] = proof
+		metadata["proof_verified"] = proof.Verified
+		if !proof.Verified {
+			metadata["proof_error"] = proof.Error
+		}
+	}
+	if opts.IncludePending {
+		pending, err := ec.pendingBalance(ctx, address, state.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not get pending state of %s", err, address.Hex())
+		}
+
+		metadata["pending"] = pending
+	}
+
+	return &RosettaTypes.AccountBalanceResponse{
+		Balances:        balances,
+		BlockIdentifier: state.Block,
+		Metadata:        metadata,
+	}, nil
+}
+
+// blockHeader returns the header of block, or of
+// the latest block if block is nil or empty.
+func (ec *Client) blockHeader(
+	ctx context.Context,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*types.Header, error) {
+	if block != nil && block.Hash != nil {
+		return ec.blockHeaderByHash(ctx, *block.Hash)
+	}
+	if block != nil && block.Index != nil {
+		return ec.blockHeaderByNumber(ctx, big.NewInt(*block.Index))
 	}
 
-	balance, err := ec.c2.BalanceAt(ctx, address, blockNum)
+	return ec.blockHeaderByNumber(ctx, nil) // latest block
+}
+
+// accountState returns the state of address at block with JSON-RPC.
+func (ec *Client) accountState(
+	ctx context.Context,
+	address common.Address,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*accountState, error) {
+	head, err := ec.blockHeader(ctx, block)
 	if err != nil {
 		return nil, err
 	}
+	blockHash := head.Hash()
+	blockQuery := toBlockHashArg(blockHash)
 
-	nonce, err := ec.c2.NonceAt(ctx, address, blockNum)
-	if err != nil {
+	// The code, balance and nonce are read from the state of the same
+	// block, which must remain canonical while they are read.
+	var code hexutil.Bytes
+	var balance hexutil.Big
+	var nonce hexutil.Uint64
+	reqs := []rpc.BatchElem{
+		{Method: "eth_getCode", Args: []interface{}{address, blockQuery}, Result: &code},
+		{Method: "eth_getBalance", Args: []interface{}{address, blockQuery}, Result: &balance},
+		{Method: "eth_getTransactionCount", Args: []interface{}{address, blockQuery}, Result: &nonce},
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
 		return nil, err
 	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, checkCanonical(req.Error, blockHash)
+		}
+	}
 
-	return &RosettaTypes.AccountBalanceResponse{
-		Balances: []*RosettaTypes.Amount{
-			{
-				Value:    balance.String(),
-				Currency: Currency,
-			},
-		},
-		BlockIdentifier: &RosettaTypes.BlockIdentifier{
-			Hash:  eth_block.Header().Hash().String(),
-			Index: eth_block.Header().Number.Int64(),
-		},
-		Metadata: map[string]interface{}{
-			"nonce": int64(nonce),
-			"code":  code,
+	return &accountState{
+		Block: &RosettaTypes.BlockIdentifier{
+			Hash:  blockHash.Hex(),
+			Index: head.Number.Int64(),
 		},
+		Balance: balance.ToInt(),
+		Nonce:   uint64(nonce),
+		Code:    code.String(),
 	}, nil
-
 }
 
 // GetBlockByNumberInput is the input to the call
@@ -1714,6 +2313,36 @@ func (ec *Client) Call(
 			return nil, err
 		}
 
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case BlockCacheStatsMethod:
+		resp, err := RosettaTypes.MarshalMap(ec.blockCache.Stats())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case AccountBalancesMethod:
+		resp, err := ec.accountBalancesCall(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case BlockByTimestampMethod:
+		resp, err := ec.blockByTimestampCall(ctx, request.Parameters)
+		if errors.Is(err, ErrInvalidTimestamp) {
+			return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+
 		return &RosettaTypes.CallResponse{
 			Result: resp,
 		}, nil
@@ -1733,30 +2362,286 @@ type txPool map[string]txPoolInner
 
 type txPoolInner map[string]rpcTransaction
 
-// GetMempool get and returns all the transactions on Findora TxPool (pending and queued).
-func (ec *Client) GetMempool(ctx context.Context) (*RosettaTypes.MempoolResponse, error) {
-	var response txPoolContentResponse
-	if err := ec.c.CallContext(ctx, &response, "txpool_content"); err != nil {
+// mempoolSource returns the source of the mempool of the node.
+func (ec *Client) mempoolSource() mempoolSource {
+	if ec.mempool == nil {
+		return &txPoolContentSource{c: ec.c}
+	}
+
+	return ec.mempool
+}
+
+// mempoolFilter filters the transactions returned by GetMempool. It is
+// read from the metadata of the request.
+type mempoolFilter struct {
+	// Address only returns the transactions sent by the address.
+	Address string `json:"address"`
+
+	// Pool only returns the transactions in the pool
+	// ("pending" or "queued").
+	Pool string `json:"pool"`
+
+	// MinGasPrice (in wei, decimal or hex) only returns the
+	// transactions paying at least this gas price for the
+	// base fee of the latest block.
+	MinGasPrice string `json:"min_gas_price"`
+
+	sender      *common.Address
+	minGasPrice *big.Int
+}
+
+// parseMempoolFilter parses the mempoolFilter in metadata.
+func parseMempoolFilter(metadata map[string]interface{}) (*mempoolFilter, error) {
+	var filter mempoolFilter
+	if err := RosettaTypes.UnmarshalMap(metadata, &filter); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMempoolFilter, err.Error())
+	}
+
+	if len(filter.Address) > 0 {
+		if !common.IsHexAddress(filter.Address) {
+			return nil, fmt.Errorf("%w: invalid address %s", ErrInvalidMempoolFilter, filter.Address)
+		}
+
+		sender := common.HexToAddress(filter.Address)
+		filter.sender = &sender
+	}
+
+	switch filter.Pool {
+	case "", pendingPool, queuedPool:
+	default:
+		return nil, fmt.Errorf("%w: invalid pool %s", ErrInvalidMempoolFilter, filter.Pool)
+	}
+
+	if len(filter.MinGasPrice) > 0 {
+		minGasPrice, ok := new(big.Int).SetString(filter.MinGasPrice, 0)
+		if !ok || minGasPrice.Sign() < 0 {
+			return nil, fmt.Errorf(
+				"%w: invalid min gas price %s",
+				ErrInvalidMempoolFilter,
+				filter.MinGasPrice,
+			)
+		}
+		filter.minGasPrice = minGasPrice
+	}
+
+	return &filter, nil
+}
+
+// GetMempool get and returns the transactions on Findora TxPool (pending and queued)
+// matching the mempoolFilter in metadata. When the TxPool can't be listed, only the
+// transactions known to the mempool source are returned.
+func (ec *Client) GetMempool(
+	ctx context.Context,
+	metadata map[string]interface{},
+) (*RosettaTypes.MempoolResponse, error) {
+	filter, err := parseMempoolFilter(metadata)
+	if err != nil {
 		return nil, err
 	}
 
-	identifiers := make([]*RosettaTypes.TransactionIdentifier, 0)
+	txs, err := ec.mempoolSource().Transactions(ctx, filter.sender)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, inner := range response.Pending {
-		for _, info := range inner {
-			identifiers = append(identifiers, &RosettaTypes.TransactionIdentifier{
-				Hash: info.tx.Hash().String(),
-			})
+	var baseFee *big.Int
+	if filter.minGasPrice != nil {
+		head, err := ec.blockHeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not get latest header", err)
 		}
+		baseFee = head.BaseFee
 	}
 
-	for _, inner := range response.Queued {
-		for _, info := range inner {
-			identifiers = append(identifiers, &RosettaTypes.TransactionIdentifier{
-				Hash: info.tx.Hash().String(),
-			})
+	identifiers := make([]*RosettaTypes.TransactionIdentifier, 0, len(txs))
+	for _, tx := range txs {
+		if len(filter.Pool) > 0 && tx.Pool != filter.Pool {
+			continue
 		}
+
+		if filter.minGasPrice != nil &&
+			pendingGasPrice(tx.Transaction.tx, baseFee).Cmp(filter.minGasPrice) < 0 {
+			continue
+		}
+
+		identifiers = append(identifiers, &RosettaTypes.TransactionIdentifier{
+			Hash: tx.Transaction.tx.Hash().String(),
+		})
 	}
 
 	return &RosettaTypes.MempoolResponse{TransactionIdentifiers: identifiers}, nil
 }
+
+// GetMempoolTransaction returns the transaction with the given identifier
+// from the Findora TxPool (pending or queued). The operations of the
+// transaction pay its estimated fee and move its value like the
+// operations of a transaction in a block, but have no status as its
+// outcome is not known yet. Queued transactions explain the nonce gap
+// keeping them from being executed.
+func (ec *Client) GetMempoolTransaction(
+	ctx context.Context,
+	transaction *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.MempoolTransactionResponse, error) {
+	hash := common.HexToHash(transaction.Hash)
+	tx, err := ec.mempoolSource().Transaction(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, hash.Hex())
+	}
+
+	head, err := ec.blockHeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get latest header", err)
+	}
+
+	loadedTx := tx.Transaction.LoadedTransaction()
+	loadedTx.FeeAmount = pendingFee(loadedTx.Transaction, head.BaseFee)
+
+	// Transactions that can't pay the base fee have no effective
+	// gas price yet: they are included once the base fee drops to
+	// their fee cap, so their fee is split at that base fee.
+	splitBaseFee := head.BaseFee
+	if head.BaseFee != nil {
+		if _, err := effectiveGasPrice(loadedTx.Transaction, head.BaseFee); err == nil {
+			loadedTx.BaseFee = head.BaseFee
+		} else {
+			splitBaseFee = loadedTx.Transaction.GasFeeCap()
+		}
+	}
+
+	// The fee is split as in /block, assuming the transaction uses
+	// all of its gas and is included by the proposer of the latest
+	// block.
+	loadedTx.FeeSplit = ec.feePolicy.Split(
+		loadedTx.FeeAmount,
+		loadedTx.Transaction.Gas(),
+		splitBaseFee,
+	)
+	loadedTx.Miner = MustChecksum(head.Coinbase.Hex())
+
+	// The outcome of the transaction is not known, assume it
+	// succeeds to find the address of created contracts
+	loadedTx.Receipt = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	if loadedTx.Transaction.To() == nil {
+		loadedTx.Receipt.ContractAddress = crypto.CreateAddress(
+			*loadedTx.From,
+			loadedTx.Transaction.Nonce(),
+		)
+	}
+
+	ops := feeOps(loadedTx)
+	ops = append(ops, transferOps(loadedTx, len(ops))...)
+	for _, op := range ops {
+		op.Status = nil
+	}
+
+	metadata, err := transactionMetadata(loadedTx)
+	if err != nil {
+		return nil, err
+	}
+	metadata["pool"] = tx.Pool
+
+	if tx.Pool == queuedPool {
+		metadata["nonce_gap"], err = ec.nonceGapMetadata(ctx, loadedTx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RosettaTypes.MempoolTransactionResponse{
+		Transaction: &RosettaTypes.Transaction{
+			TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+				Hash: hash.Hex(),
+			},
+			Operations: ops,
+			Metadata:   metadata,
+		},
+	}, nil
+}
+
+// nonceGapMetadata explains why the queued tx is not executable:
+// the transactions of its sender with the nonces between the pending
+// nonce of the sender and the nonce of tx are missing.
+func (ec *Client) nonceGapMetadata(
+	ctx context.Context,
+	tx *loadedTransaction,
+) (map[string]interface{}, error) {
+	pendingNonce, err := ec.PendingNonceAt(ctx, *tx.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get pending nonce of %s", err, tx.From.Hex())
+	}
+
+	nonce := tx.Transaction.Nonce()
+	if nonce <= pendingNonce {
+		return map[string]interface{}{
+			"pending_nonce":  hexutil.EncodeUint64(pendingNonce),
+			"missing_nonces": hexutil.EncodeUint64(0),
+			"explanation": "no transaction is missing, the sender may not be able to pay " +
+				"for the transaction or its gas price may be too low",
+		}, nil
+	}
+
+	return map[string]interface{}{
+		"pending_nonce":  hexutil.EncodeUint64(pendingNonce),
+		"missing_nonces": hexutil.EncodeUint64(nonce - pendingNonce),
+		"explanation": fmt.Sprintf(
+			"waiting for the transactions with nonces %d to %d of the sender",
+			pendingNonce,
+			nonce-1,
+		),
+	}, nil
+}
+
+// find returns the transaction with hash in r.
+func (r *txPoolContentResponse) find(hash common.Hash) (*pooledTransaction, bool) {
+	for _, tx := range r.transactions() {
+		if tx.Transaction.tx.Hash() == hash {
+			return tx, true
+		}
+	}
+
+	return nil, false
+}
+
+// transactions returns the transactions in r.
+func (r *txPoolContentResponse) transactions() []*pooledTransaction {
+	pools := []struct {
+		name string
+		pool txPool
+	}{
+		{pendingPool, r.Pending},
+		{queuedPool, r.Queued},
+	}
+
+	txs := []*pooledTransaction{}
+	for _, pool := range pools {
+		for _, inner := range pool.pool {
+			for _, info := range inner {
+				info := info
+				txs = append(txs, &pooledTransaction{Transaction: &info, Pool: pool.name})
+			}
+		}
+	}
+
+	return txs
+}
+
+// pendingGasPrice returns the gas price tx pays for baseFee. EIP-1559
+// transactions that can't pay baseFee are assumed to pay their fee
+// cap.
+func pendingGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee != nil {
+		if price, err := effectiveGasPrice(tx, baseFee); err == nil {
+			return price
+		}
+	}
+
+	return tx.GasFeeCap()
+}
+
+// pendingFee returns the highest fee tx can pay, assuming it uses all
+// of its gas at the effective gas price for baseFee. EIP-1559
+// transactions that can't pay baseFee are assumed to pay their fee
+// cap.
+func pendingFee(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), pendingGasPrice(tx, baseFee))
+}
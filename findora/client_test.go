@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -42,11 +43,9 @@ import (
 
 func TestStatus_NotReady(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -70,16 +69,13 @@ func TestStatus_NotReady(t *testing.T) {
 	assert.True(t, errors.Is(err, ethereum.NotFound))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestStatus_NotSyncing(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -120,6 +116,29 @@ func TestStatus_NotSyncing(t *testing.T) {
 		},
 	).Once()
 
+	block, timestamp, syncStatus, peers, err := c.Status(ctx)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  "0x48269a339ce1489cff6bab70eff432289c4f490b81dbd00ff1f81c68de06b842",
+		Index: 8916656,
+	}, block)
+	assert.Equal(t, int64(1603225195000), timestamp)
+	assert.Nil(t, syncStatus)
+	// Status does not query admin_peers; see TestPeers.
+	assert.Equal(t, []*RosettaTypes.Peer{}, peers)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestPeers(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
 	mockJSONRPC.On(
 		"CallContext",
 		ctx,
@@ -138,13 +157,7 @@ func TestStatus_NotSyncing(t *testing.T) {
 		},
 	).Once()
 
-	block, timestamp, syncStatus, peers, err := c.Status(ctx)
-	assert.Equal(t, &RosettaTypes.BlockIdentifier{
-		Hash:  "0x48269a339ce1489cff6bab70eff432289c4f490b81dbd00ff1f81c68de06b842",
-		Index: 8916656,
-	}, block)
-	assert.Equal(t, int64(1603225195000), timestamp)
-	assert.Nil(t, syncStatus)
+	peers, err := c.peers(ctx)
 	assert.Equal(t, []*RosettaTypes.Peer{
 		{
 			PeerID: "16dedaa93519f9ba41a50d77876aae4bfcddfa7cecf232b9abe3ab5bf0b871f3",
@@ -190,16 +203,13 @@ func TestStatus_NotSyncing(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestStatus_NotSyncing_SkipAdminCalls(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 		skipAdminCalls: true,
 	}
@@ -267,16 +277,13 @@ func TestStatus_NotSyncing_SkipAdminCalls(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestStatus_Syncing(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -319,24 +326,6 @@ func TestStatus_Syncing(t *testing.T) {
 		},
 	).Once()
 
-	mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"admin_peers",
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			info := args.Get(1).(*[]*p2p.PeerInfo)
-
-			file, err := ioutil.ReadFile("testdata/peers.json")
-			assert.NoError(t, err)
-
-			assert.NoError(t, json.Unmarshal(file, info))
-		},
-	).Once()
-
 	block, timestamp, syncStatus, peers, err := c.Status(ctx)
 	assert.Equal(t, &RosettaTypes.BlockIdentifier{
 		Hash:  "0x48269a339ce1489cff6bab70eff432289c4f490b81dbd00ff1f81c68de06b842",
@@ -347,61 +336,18 @@ func TestStatus_Syncing(t *testing.T) {
 		CurrentIndex: RosettaTypes.Int64(25),
 		TargetIndex:  RosettaTypes.Int64(8916760),
 	}, syncStatus)
-	assert.Equal(t, []*RosettaTypes.Peer{
-		{
-			PeerID: "16dedaa93519f9ba41a50d77876aae4bfcddfa7cecf232b9abe3ab5bf0b871f3",
-			Metadata: map[string]interface{}{
-				"caps": []string{
-					"eth/63",
-					"eth/64",
-					"eth/65",
-				},
-				"enode": "enode://5654cc39fd278c994c451434dfa7b1a44977c52018a87e911368b54daf795955d5a2dc2ece98be5a7e8d0eb245c8ef573c92e04e8b15363f9c713a8127fe7c7b@35.183.116.112:57510", // nolint
-				"enr":   "",
-				"name":  "Geth/v1.9.22-stable-c71a7e26/linux-amd64/go1.15",
-				"protocols": map[string]interface{}{
-					"eth": map[string]interface{}{
-						"difficulty": float64(31779242235308530),
-						"head":       "0x4a01c35e3e2627bf5a735bc9c7f336cb1e6450f93955473008ff64cf01feeef8",
-						"version":    float64(65),
-					},
-				},
-			},
-		},
-		{
-			PeerID: "1b75a634fbc9198d73413a0ced02837707d1fd09e4e90b8b90a0abac57113299",
-			Metadata: map[string]interface{}{
-				"caps": []string{
-					"eth/63",
-					"eth/64",
-					"eth/65",
-				},
-				"enode": "enode://bead1278155bfabdd51f04a6e896356da2f5687aa1f550bebc540828579522b87e22c67edf90efa651582e40c8c8037eb0f998208cab4a69b52c5e3387671b59@174.129.122.13:30303",                                                    // nolint
-				"enr":   "enr:-Je4QICGSLfIHa7vX3bdWnKqWIS7YwmLUP6JVqU5nBhxPpH_X_Uz1pZwVS8a48uESHay1nvz9FtxLYFftpMr3wvFZJ4Qg2V0aMfGhGcn75CAgmlkgnY0gmlwhK6Beg2Jc2VjcDI1NmsxoQO-rRJ4FVv6vdUfBKboljVtovVoeqH1UL68VAgoV5UiuIN0Y3CCdl-DdWRwgnZf", // nolint
-				"name":  "Geth/v1.9.15-omnibus-75eb5240/linux-amd64/go1.14.4",
-				"protocols": map[string]interface{}{
-					"eth": map[string]interface{}{
-						"difficulty": float64(31779248439556308),
-						"head":       "0x562415e43630bb6d79176ea2fa35ff2a54cee276b678b755831886b1029911bd",
-						"version":    float64(65),
-					},
-				},
-			},
-		},
-	}, peers)
+	// Status does not query admin_peers; see TestPeers.
+	assert.Equal(t, []*RosettaTypes.Peer{}, peers)
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestStatus_Syncing_SkipAdminCalls(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 		skipAdminCalls: true,
 	}
@@ -474,7 +420,6 @@ func TestStatus_Syncing_SkipAdminCalls(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestBalance(t *testing.T) {
@@ -517,6 +462,7 @@ func TestBalance(t *testing.T) {
 			Address: "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55",
 		},
 		nil,
+		nil,
 	)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
@@ -585,6 +531,7 @@ func TestBalance_Historical_Hash(t *testing.T) {
 			),
 			Index: RosettaTypes.Int64(8165),
 		},
+		nil,
 	)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
@@ -650,6 +597,7 @@ func TestBalance_Historical_Index(t *testing.T) {
 		&RosettaTypes.PartialBlockIdentifier{
 			Index: RosettaTypes.Int64(8165),
 		},
+		nil,
 	)
 	assert.Equal(t, &RosettaTypes.AccountBalanceResponse{
 		BlockIdentifier: &RosettaTypes.BlockIdentifier{
@@ -684,36 +632,16 @@ func TestBalance_InvalidAddress(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	result, err := ioutil.ReadFile("testdata/account_balance_invalid.json")
-	assert.NoError(t, err)
-	mockGraphQL.On(
-		"Query",
-		ctx,
-		`{
-			block(){
-				hash
-				number
-				account(address:"0x4cfc400fed52f9681b42454c2db4b18ab98f8de"){
-					balance
-					transactionCount
-					code
-				}
-			}
-		}`,
-	).Return(
-		string(result),
-		nil,
-	).Once()
-
 	resp, err := c.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
 			Address: "0x4cfc400fed52f9681b42454c2db4b18ab98f8de",
 		},
 		nil,
+		nil,
 	)
 	assert.Nil(t, resp)
-	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
 
 	mockJSONRPC.AssertExpectations(t)
 	mockGraphQL.AssertExpectations(t)
@@ -761,6 +689,7 @@ func TestBalance_InvalidHash(t *testing.T) {
 				"0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626",
 			),
 		},
+		nil,
 	)
 	assert.Nil(t, resp)
 	assert.Error(t, err)
@@ -771,11 +700,9 @@ func TestBalance_InvalidHash(t *testing.T) {
 
 func TestCall_GetBlockByNumber(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -824,16 +751,13 @@ func TestCall_GetBlockByNumber(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_GetBlockByNumber_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -852,16 +776,13 @@ func TestCall_GetBlockByNumber_InvalidArgs(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_GetTransactionReceipt(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -917,16 +838,13 @@ func TestCall_GetTransactionReceipt(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_GetTransactionReceipt_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -941,16 +859,13 @@ func TestCall_GetTransactionReceipt_InvalidArgs(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_Call(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1006,16 +921,13 @@ func TestCall_Call(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_Call_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1036,16 +948,13 @@ func TestCall_Call_InvalidArgs(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_EstimateGas(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1103,16 +1012,13 @@ func TestCall_EstimateGas(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1131,16 +1037,13 @@ func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestCall_InvalidMethod(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1155,7 +1058,6 @@ func TestCall_InvalidMethod(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrCallMethodInvalid))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func testTraceConfig() (*tracers.TraceConfig, error) {
@@ -1173,15 +1075,14 @@ func testTraceConfig() (*tracers.TraceConfig, error) {
 
 func TestBlock_Current(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1240,20 +1141,18 @@ func TestBlock_Current(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestBlock_Hash(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1316,20 +1215,18 @@ func TestBlock_Hash(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestBlock_Index(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1390,20 +1287,18 @@ func TestBlock_Index(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestBlock_FirstBlock(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1443,7 +1338,6 @@ func TestBlock_FirstBlock(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func jsonifyTransaction(b *RosettaTypes.Transaction) (*RosettaTypes.Transaction, error) {
@@ -1476,7 +1370,6 @@ func jsonifyBlock(b *RosettaTypes.Block) (*RosettaTypes.Block, error) {
 
 func TestTransaction_Hash(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 	txHash := "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4"
 	blockHash := "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d"
 
@@ -1484,9 +1377,9 @@ func TestTransaction_Hash(t *testing.T) {
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -1604,22 +1497,23 @@ func TestTransaction_Hash(t *testing.T) {
 	assert.Equal(t, correct.Transaction, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with transaction
 func TestBlock_10994(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -1665,7 +1559,7 @@ func TestBlock_10994(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -1710,22 +1604,23 @@ func TestBlock_10994(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with uncle
 func TestBlock_10991(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -1771,7 +1666,7 @@ func TestBlock_10991(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -1815,22 +1710,23 @@ func TestBlock_10991(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with partial success transaction
 func TestBlock_239782(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -1876,7 +1772,7 @@ func TestBlock_239782(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -1921,22 +1817,23 @@ func TestBlock_239782(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with transfer to destroyed contract
 func TestBlock_363415(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -1982,7 +1879,7 @@ func TestBlock_363415(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2033,22 +1930,23 @@ func TestBlock_363415(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with transfer to precompiled
 func TestBlock_363753(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -2094,7 +1992,7 @@ func TestBlock_363753(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2145,22 +2043,23 @@ func TestBlock_363753(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with complex self-destruct
 func TestBlock_468179(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -2206,7 +2105,7 @@ func TestBlock_468179(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2257,22 +2156,23 @@ func TestBlock_468179(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with complex resurrection
 func TestBlock_363366(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -2318,7 +2218,7 @@ func TestBlock_363366(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2370,22 +2270,23 @@ func TestBlock_363366(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with blackholed funds
 func TestBlock_468194(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -2431,7 +2332,7 @@ func TestBlock_468194(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2482,7 +2383,6 @@ func TestBlock_468194(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 // Block with EIP-1559 base fee & txs. This block taken from mainnet:
@@ -2490,16 +2390,18 @@ func TestBlock_468194(t *testing.T) {
 // This block has 7 transactions, all EIP-1559 type except the last.
 func TestBlock_13998626(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	tc, err := testTraceConfig()
 	assert.NoError(t, err)
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		tc:             tc,
 		p:              AnvilChainConfig,
+		feePolicy:      DefaultFeePolicy,
 		traceSemaphore: semaphore.NewWeighted(100),
+
+		// The receipts are fetched with eth_getTransactionReceipt batches.
+		blockReceiptsUnsupported: 1,
 	}
 
 	ctx := context.Background()
@@ -2543,7 +2445,7 @@ func TestBlock_13998626(t *testing.T) {
 	).Once()
 	mockJSONRPC.On(
 		"BatchCallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 	).Return(
 		nil,
@@ -2598,16 +2500,13 @@ func TestBlock_13998626(t *testing.T) {
 	assert.Equal(t, correctResp.Block, jsonResp)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestPendingNonceAt(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -2636,16 +2535,13 @@ func TestPendingNonceAt(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestSuggestGasPrice(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -2671,16 +2567,13 @@ func TestSuggestGasPrice(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestSendTransaction(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -2707,12 +2600,10 @@ func TestSendTransaction(t *testing.T) {
 	))
 
 	mockJSONRPC.AssertExpectations(t)
-	mockGraphQL.AssertExpectations(t)
 }
 
 func TestGetMempool(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
-	mockGraphQL := &mocks.GraphQL{}
 	ctx := context.Background()
 	expectedMempool := &RosettaTypes.MempoolResponse{
 		TransactionIdentifiers: []*RosettaTypes.TransactionIdentifier{
@@ -2737,7 +2628,6 @@ func TestGetMempool(t *testing.T) {
 
 	c := &Client{
 		c:              mockJSONRPC,
-		g:              mockGraphQL,
 		traceSemaphore: semaphore.NewWeighted(100),
 	}
 
@@ -2758,7 +2648,7 @@ func TestGetMempool(t *testing.T) {
 		},
 	).Once()
 
-	actualMempool, err := c.GetMempool(ctx)
+	actualMempool, err := c.GetMempool(ctx, nil)
 	assert.NoError(t, err)
 
 	assert.Len(t, actualMempool.TransactionIdentifiers, len(expectedMempool.TransactionIdentifiers))
@@ -2776,3 +2666,387 @@ func TestGetMempool(t *testing.T) {
 
 	mockJSONRPC.AssertExpectations(t)
 }
+
+func TestPopulateTransaction_Traces(t *testing.T) {
+	txHash := "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4"
+	c := &Client{
+		p:              AnvilChainConfig,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	rawTx, err := ioutil.ReadFile("testdata/transaction_" + txHash + ".json")
+	assert.NoError(t, err)
+	var body rpcTransaction
+	assert.NoError(t, json.Unmarshal(rawTx, &body))
+
+	rawReceipt, err := ioutil.ReadFile("testdata/tx_receipt_" + txHash + ".json")
+	assert.NoError(t, err)
+	receipt := new(types.Receipt)
+	assert.NoError(t, receipt.UnmarshalJSON(rawReceipt))
+
+	rawTrace, err := ioutil.ReadFile("testdata/transaction_trace_" + txHash + ".json")
+	assert.NoError(t, err)
+	var trace *Call
+	assert.NoError(t, json.Unmarshal(rawTrace, &trace))
+
+	loadedTx := body.LoadedTransaction()
+	loadedTx.FeeAmount = big.NewInt(21000)
+	loadedTx.FeeSplit = (&BurnFeePolicy{}).Split(big.NewInt(21000), 21000, nil)
+	loadedTx.Miner = MustChecksum("0x0000000000000000000000000000000000000000")
+	loadedTx.Receipt = receipt
+	loadedTx.Trace = trace
+	loadedTx.RawTrace = rawTrace
+
+	tx, err := c.populateTransaction(context.Background(), loadedTx)
+	assert.NoError(t, err)
+
+	// The top-level call is only reported once (by the trace operations).
+	assert.Len(t, tx.Operations, 3)
+	assert.Equal(t, FeeBurnOpType, tx.Operations[0].Type)
+	assert.Equal(t, CallOpType, tx.Operations[1].Type)
+	assert.Equal(t, "-1000000000000000000", tx.Operations[1].Amount.Value)
+	assert.Equal(t, CallOpType, tx.Operations[2].Type)
+	assert.Equal(t, "1000000000000000000", tx.Operations[2].Amount.Value)
+	assert.Equal(
+		t,
+		MustChecksum("0xc662a694fdaa5406a8ee2ca2e94890d58ab578d9"),
+		tx.Operations[2].Account.Address,
+	)
+	assert.NotNil(t, tx.Metadata["trace"])
+}
+
+func TestPopulateTransaction_ContractCreation(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{
+		p: AnvilChainConfig,
+		c: mockJSONRPC,
+	}
+
+	from := common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85")
+	contract := common.HexToAddress("0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a")
+	code := hexutil.Bytes{0x60, 0x80, 0x60, 0x40}
+
+	loadedTx := &loadedTransaction{
+		Transaction: types.NewContractCreation(0, big.NewInt(5), 100000, big.NewInt(1), code),
+		From:        &from,
+		FeeAmount:   big.NewInt(21000),
+		Miner:       MustChecksum("0x0000000000000000000000000000000000000000"),
+		Receipt: &types.Receipt{
+			Status:          types.ReceiptStatusSuccessful,
+			ContractAddress: contract,
+			BlockNumber:     big.NewInt(10),
+		},
+	}
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getCode",
+		contract,
+		"0xa",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Bytes)
+			*r = code
+		},
+	).Once()
+
+	tx, err := c.populateTransaction(ctx, loadedTx)
+	assert.NoError(t, err)
+
+	assert.Len(t, tx.Operations, 4)
+	assert.Equal(t, CreateOpType, tx.Operations[2].Type)
+	assert.Equal(t, MustChecksum(from.Hex()), tx.Operations[2].Account.Address)
+	assert.Equal(t, "-5", tx.Operations[2].Amount.Value)
+	assert.Nil(t, tx.Operations[2].Metadata)
+	assert.Equal(t, CreateOpType, tx.Operations[3].Type)
+	assert.Equal(t, MustChecksum(contract.Hex()), tx.Operations[3].Account.Address)
+	assert.Equal(t, "5", tx.Operations[3].Amount.Value)
+	assert.Equal(t, map[string]interface{}{
+		ContractAddressKey: MustChecksum(contract.Hex()),
+		"code_hash":        crypto.Keccak256Hash(code).Hex(),
+	}, tx.Operations[3].Metadata)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestTransactionMetadata(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	chainID := big.NewInt(2152)
+	signer := types.NewLondonSigner(chainID)
+	to := common.HexToAddress("0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a")
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{0x1}}}}
+
+	tests := map[string]struct {
+		tx       types.TxData
+		baseFee  *big.Int
+		expected map[string]interface{}
+	}{
+		"legacy": {
+			tx: &types.LegacyTx{
+				Nonce:    1,
+				GasPrice: big.NewInt(10),
+				Gas:      21000,
+				To:       &to,
+				Data:     []byte{0x1},
+			},
+			baseFee: big.NewInt(7),
+			expected: map[string]interface{}{
+				"type":                "0x0",
+				"nonce":               "0x1",
+				"input":               "0x01",
+				"gas_limit":           "0x5208",
+				"gas_price":           "0xa",
+				"chain_id":            "0x868",
+				"effective_gas_price": "0xa",
+			},
+		},
+		"access list": {
+			tx: &types.AccessListTx{
+				ChainID:    chainID,
+				Nonce:      2,
+				GasPrice:   big.NewInt(10),
+				Gas:        21000,
+				To:         &to,
+				AccessList: accessList,
+			},
+			expected: map[string]interface{}{
+				"type":                "0x1",
+				"nonce":               "0x2",
+				"input":               "0x",
+				"gas_limit":           "0x5208",
+				"gas_price":           "0xa",
+				"chain_id":            "0x868",
+				"access_list":         accessList,
+				"effective_gas_price": "0xa",
+			},
+		},
+		"dynamic fee": {
+			tx: &types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     3,
+				GasTipCap: big.NewInt(2),
+				GasFeeCap: big.NewInt(10),
+				Gas:       21000,
+				To:        &to,
+			},
+			baseFee: big.NewInt(7),
+			expected: map[string]interface{}{
+				"type":                     "0x2",
+				"nonce":                    "0x3",
+				"input":                    "0x",
+				"gas_limit":                "0x5208",
+				"gas_price":                "0xa",
+				"chain_id":                 "0x868",
+				"access_list":              types.AccessList{},
+				"max_fee_per_gas":          "0xa",
+				"max_priority_fee_per_gas": "0x2",
+				"effective_gas_price":      "0x9",
+			},
+		},
+		"dynamic fee without base fee": {
+			tx: &types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     3,
+				GasTipCap: big.NewInt(2),
+				GasFeeCap: big.NewInt(10),
+				Gas:       21000,
+				To:        &to,
+			},
+			expected: map[string]interface{}{
+				"type":                     "0x2",
+				"nonce":                    "0x3",
+				"input":                    "0x",
+				"gas_limit":                "0x5208",
+				"gas_price":                "0xa",
+				"chain_id":                 "0x868",
+				"access_list":              types.AccessList{},
+				"max_fee_per_gas":          "0xa",
+				"max_priority_fee_per_gas": "0x2",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx, err := types.SignNewTx(key, signer, test.tx)
+			assert.NoError(t, err)
+
+			metadata, err := transactionMetadata(&loadedTransaction{
+				Transaction: tx,
+				BaseFee:     test.baseFee,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, metadata)
+		})
+	}
+}
+
+// testMethodNotFoundError is returned by the node
+// for unsupported methods (implements rpc.Error).
+type testMethodNotFoundError struct{}
+
+func (e *testMethodNotFoundError) Error() string  { return "the method does not exist" }
+func (e *testMethodNotFoundError) ErrorCode() int { return methodNotFoundCode }
+
+func testBlockReceipts(blockHash common.Hash, n int) ([]rpcTransaction, map[common.Hash]*types.Receipt) {
+	txs := make([]rpcTransaction, n)
+	receipts := make(map[common.Hash]*types.Receipt, n)
+	for i := range txs {
+		tx := types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		txs[i] = rpcTransaction{tx: tx}
+		receipts[tx.Hash()] = &types.Receipt{
+			Status:           types.ReceiptStatusSuccessful,
+			TxHash:           tx.Hash(),
+			BlockHash:        blockHash,
+			TransactionIndex: uint(i),
+		}
+	}
+
+	return txs, receipts
+}
+
+func mockReceiptBatches(
+	mockJSONRPC *mocks.JSONRPC,
+	receipts map[common.Hash]*types.Receipt,
+	batchSize int,
+	batches int,
+) {
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			if len(r) > batchSize {
+				panic(fmt.Sprintf("batch of %d receipts", len(r)))
+			}
+
+			for i := range r {
+				hash := common.HexToHash(r[i].Args[0].(string))
+				*(r[i].Result.(**types.Receipt)) = receipts[hash]
+			}
+		},
+	).Times(batches)
+}
+
+func TestGetBlockReceipts_BlockReceipts(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+
+	blockHash := common.HexToHash("0x1")
+	txs, receipts := testBlockReceipts(blockHash, 3000)
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockReceipts",
+		blockHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*[]*types.Receipt)
+			for _, tx := range txs {
+				*r = append(*r, receipts[tx.tx.Hash()])
+			}
+		},
+	).Once()
+
+	result, err := c.getBlockReceipts(ctx, blockHash, txs)
+	assert.NoError(t, err)
+	assert.Len(t, result, len(txs))
+	for i, receipt := range result {
+		assert.Equal(t, txs[i].tx.Hash(), receipt.TxHash)
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestGetBlockReceipts_Batches(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC, receiptBatchSize: 100}
+
+	blockHash := common.HexToHash("0x1")
+	txs, receipts := testBlockReceipts(blockHash, 5050)
+
+	// eth_getBlockReceipts is only tried once when not supported
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockReceipts",
+		blockHash,
+	).Return(
+		&testMethodNotFoundError{},
+	).Once()
+	mockReceiptBatches(mockJSONRPC, receipts, 100, 2*51)
+
+	for i := 0; i < 2; i++ {
+		result, err := c.getBlockReceipts(ctx, blockHash, txs)
+		assert.NoError(t, err)
+		assert.Len(t, result, len(txs))
+		for i, receipt := range result {
+			assert.Equal(t, txs[i].tx.Hash(), receipt.TxHash)
+		}
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestGetBlockReceipts_Fallback(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+
+	blockHash := common.HexToHash("0x1")
+	txs, receipts := testBlockReceipts(blockHash, 1000)
+
+	// Other errors (i.e. a timeout) fall back to batches
+	// without disabling eth_getBlockReceipts
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockReceipts",
+		blockHash,
+	).Return(
+		errors.New("timeout"),
+	).Twice()
+	mockReceiptBatches(mockJSONRPC, receipts, DefaultReceiptBatchSize, 2*10)
+
+	for i := 0; i < 2; i++ {
+		result, err := c.getBlockReceipts(ctx, blockHash, txs)
+		assert.NoError(t, err)
+		assert.Len(t, result, len(txs))
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestGetBlockReceipts_Orphaned(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC, blockReceiptsUnsupported: 1}
+
+	blockHash := common.HexToHash("0x1")
+	txs, receipts := testBlockReceipts(common.HexToHash("0x2"), 10)
+	mockReceiptBatches(mockJSONRPC, receipts, DefaultReceiptBatchSize, 1)
+
+	result, err := c.getBlockReceipts(ctx, blockHash, txs)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrBlockOrphaned))
+
+	mockJSONRPC.AssertExpectations(t)
+}
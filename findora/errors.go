@@ -18,8 +18,17 @@ import "errors"
 
 // Client errors
 var (
-	ErrBlockOrphaned         = errors.New("block orphaned")
-	ErrCallParametersInvalid = errors.New("call parameters invalid")
-	ErrCallOutputMarshal     = errors.New("call output marshal")
-	ErrCallMethodInvalid     = errors.New("call method invalid")
+	ErrBlockOrphaned          = errors.New("block orphaned")
+	ErrCallParametersInvalid  = errors.New("call parameters invalid")
+	ErrCallOutputMarshal      = errors.New("call output marshal")
+	ErrCallMethodInvalid      = errors.New("call method invalid")
+	ErrCurrencyNotSupported   = errors.New("currency not supported")
+	ErrInvalidAddress         = errors.New("invalid address")
+	ErrInvalidBlockHash       = errors.New("invalid block hash")
+	ErrInvalidContractAddress = errors.New("invalid contract address")
+	ErrInvalidBalanceOptions  = errors.New("invalid balance options")
+	ErrInvalidMempoolFilter   = errors.New("invalid mempool filter")
+	ErrInvalidTimestamp       = errors.New("invalid timestamp")
+	ErrTokenCallFailed        = errors.New("token call failed")
+	ErrTransactionNotFound    = errors.New("transaction not found")
 )
@@ -0,0 +1,117 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// basisPoints is the denominator of TreasuryFeePolicy.Share.
+const basisPoints = 10000
+
+// FeeSplit is how the fee paid by a transaction is distributed.
+// Burned, Tip and Treasury always add up to the fee.
+type FeeSplit struct {
+	// Burned is removed from the supply.
+	Burned *big.Int
+
+	// Tip is paid to the block proposer.
+	Tip *big.Int
+
+	// Treasury is paid to TreasuryAddress.
+	Treasury        *big.Int
+	TreasuryAddress common.Address
+}
+
+// FeePolicy decides how the fee paid by a transaction is split between
+// burning, the block proposer and a treasury. baseFee is nil in blocks
+// produced before EIP-1559.
+type FeePolicy interface {
+	Split(fee *big.Int, gasUsed uint64, baseFee *big.Int) *FeeSplit
+}
+
+// BurnFeePolicy burns the whole fee.
+type BurnFeePolicy struct{}
+
+// Split implements FeePolicy.
+func (p *BurnFeePolicy) Split(fee *big.Int, gasUsed uint64, baseFee *big.Int) *FeeSplit {
+	return &FeeSplit{
+		Burned:   new(big.Int).Set(fee),
+		Tip:      new(big.Int),
+		Treasury: new(big.Int),
+	}
+}
+
+// EIP1559FeePolicy burns the base fee and pays the rest of the fee
+// to the block proposer. The whole fee is paid to the block proposer
+// in blocks without a base fee.
+type EIP1559FeePolicy struct{}
+
+// Split implements FeePolicy.
+func (p *EIP1559FeePolicy) Split(fee *big.Int, gasUsed uint64, baseFee *big.Int) *FeeSplit {
+	burned := new(big.Int)
+	if baseFee != nil {
+		burned.Mul(new(big.Int).SetUint64(gasUsed), baseFee)
+	}
+
+	return &FeeSplit{
+		Burned:   burned,
+		Tip:      new(big.Int).Sub(fee, burned),
+		Treasury: new(big.Int),
+	}
+}
+
+// FindoraFeePolicy follows EIP1559FeePolicy in blocks with a base
+// fee. The legacy Findora EVM burns the whole fee.
+type FindoraFeePolicy struct{}
+
+// Split implements FeePolicy.
+func (p *FindoraFeePolicy) Split(fee *big.Int, gasUsed uint64, baseFee *big.Int) *FeeSplit {
+	if baseFee == nil {
+		return (&BurnFeePolicy{}).Split(fee, gasUsed, baseFee)
+	}
+
+	return (&EIP1559FeePolicy{}).Split(fee, gasUsed, baseFee)
+}
+
+// TreasuryFeePolicy pays Share basis points of the tip decided
+// by Policy to Address instead of the block proposer.
+type TreasuryFeePolicy struct {
+	Policy  FeePolicy
+	Address common.Address
+	Share   uint64
+}
+
+// Split implements FeePolicy.
+func (p *TreasuryFeePolicy) Split(fee *big.Int, gasUsed uint64, baseFee *big.Int) *FeeSplit {
+	split := p.Policy.Split(fee, gasUsed, baseFee)
+
+	share := new(big.Int).SetUint64(p.Share)
+	if share.Cmp(big.NewInt(basisPoints)) > 0 {
+		share.SetUint64(basisPoints)
+	}
+
+	treasury := new(big.Int).Mul(split.Tip, share)
+	treasury.Div(treasury, big.NewInt(basisPoints))
+
+	return &FeeSplit{
+		Burned:          split.Burned,
+		Tip:             new(big.Int).Sub(split.Tip, treasury),
+		Treasury:        treasury,
+		TreasuryAddress: p.Address,
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTreasury = common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+func TestFeePolicies(t *testing.T) {
+	tests := map[string]struct {
+		policy   FeePolicy
+		baseFee  *big.Int
+		expected *FeeSplit
+	}{
+		"burn": {
+			policy:   &BurnFeePolicy{},
+			baseFee:  big.NewInt(7),
+			expected: &FeeSplit{Burned: big.NewInt(210), Tip: big.NewInt(0), Treasury: big.NewInt(0)},
+		},
+		"eip1559": {
+			policy:   &EIP1559FeePolicy{},
+			baseFee:  big.NewInt(7),
+			expected: &FeeSplit{Burned: big.NewInt(147), Tip: big.NewInt(63), Treasury: big.NewInt(0)},
+		},
+		"eip1559 without base fee": {
+			policy:   &EIP1559FeePolicy{},
+			expected: &FeeSplit{Burned: big.NewInt(0), Tip: big.NewInt(210), Treasury: big.NewInt(0)},
+		},
+		"findora": {
+			policy:   &FindoraFeePolicy{},
+			baseFee:  big.NewInt(7),
+			expected: &FeeSplit{Burned: big.NewInt(147), Tip: big.NewInt(63), Treasury: big.NewInt(0)},
+		},
+		"findora without base fee": {
+			policy:   &FindoraFeePolicy{},
+			expected: &FeeSplit{Burned: big.NewInt(210), Tip: big.NewInt(0), Treasury: big.NewInt(0)},
+		},
+		"treasury": {
+			policy:  &TreasuryFeePolicy{Policy: &EIP1559FeePolicy{}, Address: testTreasury, Share: 2500},
+			baseFee: big.NewInt(7),
+			expected: &FeeSplit{
+				Burned:          big.NewInt(147),
+				Tip:             big.NewInt(48),
+				Treasury:        big.NewInt(15),
+				TreasuryAddress: testTreasury,
+			},
+		},
+		"treasury share capped": {
+			policy:  &TreasuryFeePolicy{Policy: &EIP1559FeePolicy{}, Address: testTreasury, Share: 20000},
+			baseFee: big.NewInt(7),
+			expected: &FeeSplit{
+				Burned:          big.NewInt(147),
+				Tip:             big.NewInt(0),
+				Treasury:        big.NewInt(63),
+				TreasuryAddress: testTreasury,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// 21 gas used at a price of 10 (base fee 7 + tip 3)
+			split := test.policy.Split(big.NewInt(210), 21, test.baseFee)
+			assert.Equal(t, test.expected.Burned.String(), split.Burned.String())
+			assert.Equal(t, test.expected.Tip.String(), split.Tip.String())
+			assert.Equal(t, test.expected.Treasury.String(), split.Treasury.String())
+			assert.Equal(t, test.expected.TreasuryAddress, split.TreasuryAddress)
+		})
+	}
+}
+
+func TestFeeOps(t *testing.T) {
+	from := common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85")
+	miner := common.HexToAddress("0xc662a694fdaa5406a8ee2ca2e94890d58ab578d9")
+	fee := big.NewInt(210)
+	tx := &loadedTransaction{
+		From:      &from,
+		FeeAmount: fee,
+		Miner:     miner.Hex(),
+		FeeSplit: (&TreasuryFeePolicy{
+			Policy:  &EIP1559FeePolicy{},
+			Address: testTreasury,
+			Share:   2500,
+		}).Split(fee, 21, big.NewInt(7)),
+	}
+
+	ops := feeOps(tx)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+			Type:                FeeOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(from.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-63", Currency: Currency},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 0}},
+			Type:                FeeTipOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(miner.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "48", Currency: Currency},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 2},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 0}},
+			Type:                FeeTreasuryOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTreasury.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "15", Currency: Currency},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 3},
+			Type:                FeeBurnOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(from.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-147", Currency: Currency},
+		},
+	}, ops)
+
+	// The operations add up to the supply change (the burned fee)
+	sum := new(big.Int)
+	for _, op := range ops {
+		value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+		assert.True(t, ok)
+		sum.Add(sum, value)
+	}
+	assert.Equal(t, big.NewInt(-147), sum)
+}
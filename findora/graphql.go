@@ -0,0 +1,358 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+)
+
+const (
+	// graphQLProbeTimeout is the timeout of the
+	// queries checking if GraphQL is exposed.
+	graphQLProbeTimeout = 5 * time.Second
+
+	// graphQLProbeQuery succeeds if the node exposes GraphQL.
+	graphQLProbeQuery = `{ block { number } }`
+
+	// graphQLBlocksProbeQuery succeeds if the GraphQL schema
+	// of the node has the fields needed to fetch blocks.
+	graphQLBlocksProbeQuery = `{ block(number: 0) { rawHeader transactions { raw rawReceipt } } }`
+
+	// graphQLBlockQuery fetches a block with its transactions
+	// and receipts (including logs) in their consensus encoding.
+	graphQLBlockQuery = `{
+	block(%s){
+		hash
+		rawHeader
+		ommers {
+			rawHeader
+		}
+		transactions {
+			from {
+				address
+			}
+			raw
+			rawReceipt
+		}
+	}
+}`
+)
+
+type graphqlError struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path"`
+}
+
+type graphqlResponse struct {
+	Errors []graphqlError   `json:"errors"`
+	Data   *json.RawMessage `json:"data"`
+}
+
+type graphqlBalance struct {
+	Errors []graphqlError `json:"errors"`
+	Data   struct {
+		Block struct {
+			Hash    string `json:"hash"`
+			Number  int64  `json:"number"`
+			Account struct {
+				Balance string `json:"balance"`
+				Nonce   string `json:"transactionCount"`
+				Code    string `json:"code"`
+			} `json:"account"`
+		} `json:"block"`
+	} `json:"data"`
+}
+
+type graphqlBlock struct {
+	Errors []graphqlError `json:"errors"`
+	Data   struct {
+		Block *struct {
+			Hash      common.Hash   `json:"hash"`
+			RawHeader hexutil.Bytes `json:"rawHeader"`
+			Ommers    []struct {
+				RawHeader hexutil.Bytes `json:"rawHeader"`
+			} `json:"ommers"`
+			Transactions []struct {
+				From struct {
+					Address common.Address `json:"address"`
+				} `json:"from"`
+				Raw        hexutil.Bytes `json:"raw"`
+				RawReceipt hexutil.Bytes `json:"rawReceipt"`
+			} `json:"transactions"`
+		} `json:"block"`
+	} `json:"data"`
+}
+
+// probeGraphQL returns g if the node exposes GraphQL (nil otherwise)
+// and whether blocks can be fetched with GraphQL.
+func probeGraphQL(g GraphQL) (GraphQL, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), graphQLProbeTimeout)
+	defer cancel()
+
+	if !graphQLQuerySucceeds(ctx, g, graphQLProbeQuery) {
+		return nil, false
+	}
+
+	return g, graphQLQuerySucceeds(ctx, g, graphQLBlocksProbeQuery)
+}
+
+// graphQLQuerySucceeds returns true if query returns data without errors.
+func graphQLQuerySucceeds(ctx context.Context, g GraphQL, query string) bool {
+	result, err := g.Query(ctx, query)
+	if err != nil {
+		return false
+	}
+
+	var resp graphqlResponse
+	if err := json.Unmarshal([]byte(result), &resp); err != nil {
+		return false
+	}
+
+	return len(resp.Errors) == 0 && resp.Data != nil
+}
+
+// graphQLAccountState returns the state of address at block with a
+// single GraphQL query. The address and block hash are validated
+// before being inlined in the query.
+func (ec *Client) graphQLAccountState(
+	ctx context.Context,
+	address string,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*accountState, error) {
+	checksum, ok := ChecksumAddress(address)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+
+	var requestedHash *common.Hash
+	blockQuery := ""
+	if block != nil {
+		if block.Hash != nil {
+			hash, err := parseBlockHash(*block.Hash)
+			if err != nil {
+				return nil, err
+			}
+
+			requestedHash = &hash
+			blockQuery = fmt.Sprintf(`hash: "%s"`, hash.Hex())
+		}
+		if block.Hash == nil && block.Index != nil {
+			blockQuery = fmt.Sprintf("number: %d", *block.Index)
+		}
+	}
+
+	result, err := ec.g.Query(ctx, fmt.Sprintf(`{
+			block(%s){
+				hash
+				number
+				account(address:"%s"){
+					balance
+					transactionCount
+					code
+				}
+			}
+		}`, blockQuery, checksum))
+	if err != nil {
+		return nil, err
+	}
+
+	var bal graphqlBalance
+	if err := json.Unmarshal([]byte(result), &bal); err != nil {
+		return nil, err
+	}
+
+	if len(bal.Errors) > 0 {
+		return nil, errors.New(RosettaTypes.PrintStruct(bal.Errors))
+	}
+
+	if requestedHash != nil && common.HexToHash(bal.Data.Block.Hash) != *requestedHash {
+		return nil, fmt.Errorf(
+			"%w: requested block %s but got %s",
+			ErrBlockOrphaned,
+			requestedHash.Hex(),
+			bal.Data.Block.Hash,
+		)
+	}
+
+	balance, err := hexutil.DecodeBig(bal.Data.Block.Account.Balance)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: could not extract account balance from %s",
+			err,
+			bal.Data.Block.Account.Balance,
+		)
+	}
+	nonce, err := hexutil.DecodeUint64(bal.Data.Block.Account.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: could not extract account nonce from %s",
+			err,
+			bal.Data.Block.Account.Nonce,
+		)
+	}
+
+	return &accountState{
+		Block: &RosettaTypes.BlockIdentifier{
+			Hash:  bal.Data.Block.Hash,
+			Index: bal.Data.Block.Number,
+		},
+		Balance: balance,
+		Nonce:   nonce,
+		Code:    bal.Data.Block.Account.Code,
+	}, nil
+}
+
+// graphQLBlockSelector returns the arguments of the GraphQL block
+// field selecting the block requested with the JSON-RPC blockMethod.
+func graphQLBlockSelector(blockMethod string, args []interface{}) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("no block selected for %s", blockMethod)
+	}
+
+	switch blockMethod {
+	case "eth_getBlockByHash":
+		hash, err := parseBlockHash(fmt.Sprint(args[0]))
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf(`hash: "%s"`, hash.Hex()), nil
+	case "eth_getBlockByNumber":
+		if args[0] == "latest" {
+			return "", nil
+		}
+
+		number, err := hexutil.DecodeUint64(fmt.Sprint(args[0]))
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid block number %v", err, args[0])
+		}
+
+		return fmt.Sprintf("number: %d", number), nil
+	default:
+		return "", fmt.Errorf("%s is not supported with GraphQL", blockMethod)
+	}
+}
+
+// parseBlockHash parses a 32-byte hex block hash, so that
+// only valid hashes are inlined in GraphQL queries.
+func parseBlockHash(hash string) (common.Hash, error) {
+	b, err := hexutil.Decode(hash)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: %s", ErrInvalidBlockHash, hash)
+	}
+
+	return common.BytesToHash(b), nil
+}
+
+// getGraphQLBlock fetches the block requested with the JSON-RPC
+// blockMethod with its transactions, receipts and logs in a single
+// GraphQL query.
+func (ec *Client) getGraphQLBlock(
+	ctx context.Context,
+	blockMethod string,
+	args ...interface{},
+) (
+	*types.Block,
+	[]*loadedTransaction,
+	error,
+) {
+	selector, err := graphQLBlockSelector(blockMethod, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := ec.g.Query(ctx, fmt.Sprintf(graphQLBlockQuery, selector))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: block fetch failed", err)
+	}
+
+	var resp graphqlBlock
+	if err := json.Unmarshal([]byte(result), &resp); err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, nil, errors.New(RosettaTypes.PrintStruct(resp.Errors))
+	}
+
+	block := resp.Data.Block
+	if block == nil {
+		return nil, nil, ethereum.NotFound
+	}
+
+	head := new(types.Header)
+	if err := rlp.DecodeBytes(block.RawHeader, head); err != nil {
+		return nil, nil, fmt.Errorf("%w: could not decode header of %s", err, block.Hash.Hex())
+	}
+	if head.Hash() != block.Hash {
+		return nil, nil, fmt.Errorf(
+			"expected header hash %s but got %s",
+			block.Hash.Hex(),
+			head.Hash().Hex(),
+		)
+	}
+
+	uncles := make([]*types.Header, len(block.Ommers))
+	for i, ommer := range block.Ommers {
+		uncles[i] = new(types.Header)
+		if err := rlp.DecodeBytes(ommer.RawHeader, uncles[i]); err != nil {
+			return nil, nil, fmt.Errorf("%w: could not decode uncle %d of %s", err, i, block.Hash.Hex())
+		}
+	}
+
+	blockNumber := hexutil.EncodeBig(head.Number)
+	txs := make(types.Transactions, len(block.Transactions))
+	transactions := make([]rpcTransaction, len(block.Transactions))
+	receipts := make(types.Receipts, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		txs[i] = new(types.Transaction)
+		if err := txs[i].UnmarshalBinary(tx.Raw); err != nil {
+			return nil, nil, fmt.Errorf("%w: could not decode transaction %d of %s", err, i, block.Hash.Hex())
+		}
+
+		receipts[i] = new(types.Receipt)
+		if err := receipts[i].UnmarshalBinary(tx.RawReceipt); err != nil {
+			return nil, nil, fmt.Errorf("%w: could not decode receipt of %s", err, txs[i].Hash().Hex())
+		}
+
+		from := tx.From.Address
+		transactions[i] = rpcTransaction{
+			tx: txs[i],
+			txExtraInfo: txExtraInfo{
+				BlockNumber: &blockNumber,
+				BlockHash:   &block.Hash,
+				From:        &from,
+			},
+		}
+	}
+
+	// Consensus encoded receipts do not include the fields
+	// derived from the block and its transactions
+	if err := receipts.DeriveFields(ec.p, block.Hash, head.Number.Uint64(), txs); err != nil {
+		return nil, nil, fmt.Errorf("%w: could not derive receipt fields of %s", err, block.Hash.Hex())
+	}
+
+	return ec.loadBlock(ctx, head, block.Hash, transactions, uncles, receipts)
+}
@@ -0,0 +1,292 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProbeGraphQL(t *testing.T) {
+	tests := map[string]struct {
+		probe       string
+		blocksProbe string
+		available   bool
+		blocks      bool
+	}{
+		"not exposed": {
+			probe: "404 page not found",
+		},
+		"old schema": {
+			probe:       `{"data":{"block":{"number":10}}}`,
+			blocksProbe: `{"errors":[{"message":"Cannot query field \"rawHeader\" on type \"Block\"."}]}`,
+			available:   true,
+		},
+		"exposed": {
+			probe:       `{"data":{"block":{"number":10}}}`,
+			blocksProbe: `{"data":{"block":{"rawHeader":"0x","transactions":[]}}}`,
+			available:   true,
+			blocks:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockGraphQL := &mocks.GraphQL{}
+			mockGraphQL.On("Query", mock.Anything, graphQLProbeQuery).Return(test.probe, nil).Once()
+			if test.available {
+				mockGraphQL.On(
+					"Query",
+					mock.Anything,
+					graphQLBlocksProbeQuery,
+				).Return(
+					test.blocksProbe,
+					nil,
+				).Once()
+			}
+
+			g, blocks := probeGraphQL(mockGraphQL)
+			assert.Equal(t, test.available, g != nil)
+			assert.Equal(t, test.blocks, blocks)
+
+			mockGraphQL.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGraphQLBlockSelector(t *testing.T) {
+	selector, err := graphQLBlockSelector("eth_getBlockByNumber", []interface{}{"latest", true})
+	assert.NoError(t, err)
+	assert.Equal(t, "", selector)
+
+	selector, err = graphQLBlockSelector("eth_getBlockByNumber", []interface{}{"0x1f", true})
+	assert.NoError(t, err)
+	assert.Equal(t, "number: 31", selector)
+
+	hash := "0x9999286598EDF07606228BA0233736E544A086A8822C61F9DB3706887FC25DDA"
+	selector, err = graphQLBlockSelector("eth_getBlockByHash", []interface{}{hash, true})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`hash: "0x9999286598edf07606228ba0233736e544a086a8822c61f9db3706887fc25dda"`,
+		selector,
+	)
+
+	_, err = graphQLBlockSelector("eth_getBlockByHash", []interface{}{"0xabcd", true})
+	assert.True(t, errors.Is(err, ErrInvalidBlockHash))
+
+	_, err = graphQLBlockSelector("eth_getBlockByNumber", []interface{}{"pending", true})
+	assert.Error(t, err)
+}
+
+func TestBlock_GraphQL(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	config := params.AllEthashProtocolChanges
+	c := &Client{
+		p:             config,
+		c:             mockJSONRPC,
+		g:             mockGraphQL,
+		graphQLBlocks: true,
+		feePolicy:     &FindoraFeePolicy{},
+	}
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55")
+
+	tx, err := types.SignNewTx(
+		key,
+		types.LatestSignerForChainID(config.ChainID),
+		&types.LegacyTx{
+			Nonce:    3,
+			To:       &to,
+			Value:    big.NewInt(1000),
+			Gas:      21000,
+			GasPrice: big.NewInt(10),
+		},
+	)
+	assert.NoError(t, err)
+	receipt := &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	header := &types.Header{
+		ParentHash: common.HexToHash("0x01"),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   common.HexToAddress("0x02"),
+		TxHash:     types.DeriveSha(types.Transactions{tx}, trie.NewStackTrie(nil)),
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(31),
+		GasLimit:   30000000,
+		GasUsed:    21000,
+		Time:       1600000000,
+		BaseFee:    big.NewInt(1),
+	}
+
+	rawHeader, err := rlp.EncodeToBytes(header)
+	assert.NoError(t, err)
+	rawTx, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+	rawReceipt, err := receipt.MarshalBinary()
+	assert.NoError(t, err)
+
+	result, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"block": map[string]interface{}{
+				"hash":      header.Hash(),
+				"rawHeader": hexutil.Bytes(rawHeader),
+				"ommers":    []interface{}{},
+				"transactions": []interface{}{
+					map[string]interface{}{
+						"from":       map[string]interface{}{"address": from},
+						"raw":        hexutil.Bytes(rawTx),
+						"rawReceipt": hexutil.Bytes(rawReceipt),
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	mockGraphQL.On(
+		"Query",
+		ctx,
+		fmt.Sprintf(graphQLBlockQuery, "number: 31"),
+	).Return(
+		string(result),
+		nil,
+	).Once()
+
+	block, err := c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{
+		Index: RosettaTypes.Int64(31),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.BlockIdentifier{
+		Hash:  header.Hash().Hex(),
+		Index: 31,
+	}, block.BlockIdentifier)
+	assert.Equal(t, header.ParentHash.Hex(), block.ParentBlockIdentifier.Hash)
+	assert.Len(t, block.Transactions, 1)
+
+	transaction := block.Transactions[0]
+	assert.Equal(t, tx.Hash().Hex(), transaction.TransactionIdentifier.Hash)
+
+	// Receipt fields not in the consensus encoding are derived
+	receiptMetadata := transaction.Metadata["receipt"].(map[string]interface{})
+	assert.Equal(t, hexutil.Uint64(21000), receiptMetadata["gasUsed"])
+	assert.Equal(t, tx.Hash(), receiptMetadata["transactionHash"])
+	assert.Equal(t, header.Hash(), receiptMetadata["blockHash"])
+
+	var debited, credited bool
+	for _, op := range transaction.Operations {
+		if op.Type != CallOpType {
+			continue
+		}
+
+		switch op.Account.Address {
+		case MustChecksum(from.Hex()):
+			debited = op.Amount.Value == "-1000"
+		case MustChecksum(to.Hex()):
+			credited = op.Amount.Value == "1000"
+		}
+	}
+	assert.True(t, debited)
+	assert.True(t, credited)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestBlock_GraphQLErrors(t *testing.T) {
+	mockGraphQL := &mocks.GraphQL{}
+	c := &Client{g: mockGraphQL, graphQLBlocks: true}
+
+	ctx := context.Background()
+	mockGraphQL.On(
+		"Query",
+		ctx,
+		fmt.Sprintf(graphQLBlockQuery, `hash: "0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626"`),
+	).Return(
+		`{"errors":[{"message":"header for hash not found","path":["block"]}],"data":{"block":null}}`,
+		nil,
+	).Once()
+
+	block, err := c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{
+		Hash: RosettaTypes.String("0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626"),
+	})
+	assert.Nil(t, block)
+	assert.Contains(t, err.Error(), "header for hash not found")
+
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestGraphQLAccountState(t *testing.T) {
+	ctx := context.Background()
+	mockGraphQL := &mocks.GraphQL{}
+	c := &Client{g: mockGraphQL}
+
+	address := "0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"
+
+	// Inputs are validated before the query is built
+	_, err := c.graphQLAccountState(ctx, `0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55"){}}`, nil)
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+
+	_, err = c.graphQLAccountState(ctx, address, &RosettaTypes.PartialBlockIdentifier{
+		Hash: RosettaTypes.String(`0x1"){hash}}`),
+	})
+	assert.True(t, errors.Is(err, ErrInvalidBlockHash))
+
+	// A different block is returned when the requested one is not canonical
+	result, err := ioutil.ReadFile(
+		"testdata/account_balance_0x4cfc400fed52f9681b42454c2db4b18ab98f8de1.json",
+	)
+	assert.NoError(t, err)
+	mockGraphQL.On("Query", ctx, mock.Anything).Return(string(result), nil).Once()
+
+	_, err = c.graphQLAccountState(ctx, address, &RosettaTypes.PartialBlockIdentifier{
+		Hash: RosettaTypes.String(
+			"0x7d2a2713026a0e66f131878de2bb2df2fff6c24562c1df61ec0265e5fedf2626",
+		),
+	})
+	assert.True(t, errors.Is(err, ErrBlockOrphaned))
+
+	mockGraphQL.AssertExpectations(t)
+}
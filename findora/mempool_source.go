@@ -0,0 +1,661 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// pendingPool and queuedPool are the pools of the TxPool.
+	pendingPool = "pending"
+	queuedPool  = "queued"
+
+	// mempoolProbeTimeout is the timeout of the
+	// requests detecting the mempool source.
+	mempoolProbeTimeout = 10 * time.Second
+
+	// mempoolBatchSize is the maximum number of requests
+	// sent in a single batch by a mempool source.
+	mempoolBatchSize = 100
+
+	// trackedTransactionsSize is the maximum number of
+	// transactions tracked by trackedTransactions.
+	trackedTransactionsSize = 4096
+
+	// pendingTxBuffer is the size of the buffer of hashes received
+	// from the newPendingTransactions subscription.
+	pendingTxBuffer = 256
+
+	// pendingTxResubscribeInterval is the interval at which a failed
+	// newPendingTransactions subscription is retried.
+	pendingTxResubscribeInterval = 10 * time.Second
+)
+
+// pooledTransaction is a transaction in the mempool
+// and the pool ("pending" or "queued") it is in.
+type pooledTransaction struct {
+	Transaction *rpcTransaction
+	Pool        string
+}
+
+// mempoolSource lists the transactions waiting in the mempool of
+// the node. Not every node exposes its TxPool, so the source is
+// detected by detectMempoolSource.
+type mempoolSource interface {
+	// Transactions returns the transactions in the mempool, or
+	// only the ones sent by sender when it is not nil.
+	Transactions(ctx context.Context, sender *common.Address) ([]*pooledTransaction, error)
+
+	// Transaction returns the transaction with hash, or
+	// ErrTransactionNotFound if it is not in the mempool.
+	Transaction(ctx context.Context, hash common.Hash) (*pooledTransaction, error)
+
+	// Close stops the background work of the source.
+	Close()
+}
+
+// detectMempoolSource returns the most complete mempool source the
+// node supports, in order:
+//  1. txpool_content, listing the whole TxPool (and
+//     txpool_contentFrom for the transactions of a sender)
+//  2. txpool_contentFrom, listing the transactions of the senders of
+//     the transactions in submitted
+//  3. a newPendingTransactions subscription on wsURL, tracking the
+//     transactions entering the TxPool (and the ones in submitted)
+//  4. the transactions in submitted
+func detectMempoolSource(
+	c JSONRPC,
+	wsURL string,
+	submitted *trackedTransactions,
+) mempoolSource {
+	ctx, cancel := context.WithTimeout(context.Background(), mempoolProbeTimeout)
+	defer cancel()
+
+	var contentFrom txPoolContentFromResponse
+	contentFromErr := c.CallContext(ctx, &contentFrom, "txpool_contentFrom", common.Address{})
+
+	var content txPoolContentResponse
+	if err := c.CallContext(ctx, &content, "txpool_content"); err == nil {
+		return &txPoolContentSource{c: c, contentFrom: contentFromErr == nil}
+	}
+
+	if contentFromErr == nil {
+		log.Println("txpool_content is not supported, listing the mempool with txpool_contentFrom")
+		return &txPoolContentFromSource{c: c, submitted: submitted}
+	}
+
+	if len(wsURL) > 0 {
+		subscription, err := newPendingTxSubscription(ctx, wsPendingTxSubscriber(wsURL), submitted)
+		if err == nil {
+			log.Println("txpool_* is not supported, tracking the mempool with newPendingTransactions")
+			return &trackedSource{c: c, tracked: submitted, subscription: subscription}
+		}
+	}
+
+	log.Println("txpool_* is not supported, only listing submitted transactions in the mempool")
+	return &trackedSource{c: c, tracked: submitted}
+}
+
+// txPoolContentSource lists the mempool with txpool_content,
+// and the transactions of a sender with txpool_contentFrom
+// when supported.
+type txPoolContentSource struct {
+	c           JSONRPC
+	contentFrom bool
+}
+
+// Transactions implements mempoolSource.
+func (s *txPoolContentSource) Transactions(
+	ctx context.Context,
+	sender *common.Address,
+) ([]*pooledTransaction, error) {
+	if sender != nil && s.contentFrom {
+		var response txPoolContentFromResponse
+		if err := s.c.CallContext(ctx, &response, "txpool_contentFrom", *sender); err != nil {
+			return nil, err
+		}
+
+		return response.content(*sender).transactions(), nil
+	}
+
+	var response txPoolContentResponse
+	if err := s.c.CallContext(ctx, &response, "txpool_content"); err != nil {
+		return nil, err
+	}
+
+	return filterSender(response.transactions(), sender), nil
+}
+
+// Transaction implements mempoolSource.
+func (s *txPoolContentSource) Transaction(
+	ctx context.Context,
+	hash common.Hash,
+) (*pooledTransaction, error) {
+	var response txPoolContentResponse
+	if err := s.c.CallContext(ctx, &response, "txpool_content"); err != nil {
+		return nil, err
+	}
+
+	tx, ok := response.find(hash)
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	return tx, nil
+}
+
+// Close implements mempoolSource.
+func (s *txPoolContentSource) Close() {}
+
+// txPoolContentFromResponse represents the response for a call to
+// findora node on the "txpool_contentFrom" method.
+type txPoolContentFromResponse struct {
+	Pending txPoolInner `json:"pending"`
+	Queued  txPoolInner `json:"queued"`
+}
+
+// content returns r as the content of the TxPool.
+func (r *txPoolContentFromResponse) content(sender common.Address) *txPoolContentResponse {
+	return &txPoolContentResponse{
+		Pending: txPool{sender.Hex(): r.Pending},
+		Queued:  txPool{sender.Hex(): r.Queued},
+	}
+}
+
+// txPoolContentFromSource lists the mempool with txpool_contentFrom.
+// As the TxPool can only be listed by sender, only the transactions
+// of the senders of submitted transactions are listed (or of the
+// requested sender). Single transactions are looked up by the sender
+// returned by eth_getTransactionByHash.
+type txPoolContentFromSource struct {
+	c         JSONRPC
+	submitted *trackedTransactions
+}
+
+// Transactions implements mempoolSource.
+func (s *txPoolContentFromSource) Transactions(
+	ctx context.Context,
+	sender *common.Address,
+) ([]*pooledTransaction, error) {
+	senders := []common.Address{}
+	if sender != nil {
+		senders = append(senders, *sender)
+	} else {
+		senders = s.submitted.Senders()
+	}
+
+	responses := make([]txPoolContentFromResponse, len(senders))
+	reqs := make([]rpc.BatchElem, len(senders))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "txpool_contentFrom",
+			Args:   []interface{}{senders[i]},
+			Result: &responses[i],
+		}
+	}
+
+	if err := batchCall(ctx, s.c, reqs); err != nil {
+		return nil, err
+	}
+
+	var txs []*pooledTransaction
+	inPool := map[common.Hash]bool{}
+	for i := range responses {
+		if reqs[i].Error != nil {
+			return nil, reqs[i].Error
+		}
+
+		for _, tx := range responses[i].content(senders[i]).transactions() {
+			txs = append(txs, tx)
+			inPool[tx.Transaction.tx.Hash()] = true
+		}
+	}
+
+	// Submitted transactions that left the TxPool are not tracked
+	// anymore (the transactions of every sender are only known when
+	// no sender is requested)
+	if sender == nil {
+		for _, hash := range s.submitted.Hashes() {
+			if !inPool[hash] {
+				s.submitted.Remove(hash)
+			}
+		}
+	}
+
+	return txs, nil
+}
+
+// Transaction implements mempoolSource.
+func (s *txPoolContentFromSource) Transaction(
+	ctx context.Context,
+	hash common.Hash,
+) (*pooledTransaction, error) {
+	tx, err := pendingTransactionByHash(ctx, s.c, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var response txPoolContentFromResponse
+	if err := s.c.CallContext(ctx, &response, "txpool_contentFrom", *tx.From); err != nil {
+		return nil, err
+	}
+
+	// The transaction may have been included since it was fetched
+	pooled, ok := response.content(*tx.From).find(hash)
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	return pooled, nil
+}
+
+// Close implements mempoolSource.
+func (s *txPoolContentFromSource) Close() {}
+
+// trackedSource lists the tracked transactions that are still pending
+// according to eth_getTransactionByHash. As eth_getTransactionByHash
+// does not return the pool of a transaction, transactions are queued
+// if their nonce is not below the pending nonce of their sender.
+type trackedSource struct {
+	c            JSONRPC
+	tracked      *trackedTransactions
+	subscription *pendingTxSubscription // nil if not subscribed
+}
+
+// Transactions implements mempoolSource.
+func (s *trackedSource) Transactions(
+	ctx context.Context,
+	sender *common.Address,
+) ([]*pooledTransaction, error) {
+	hashes := s.tracked.Hashes()
+	txs := make([]*rpcTransaction, len(hashes))
+	reqs := make([]rpc.BatchElem, len(hashes))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionByHash",
+			Args:   []interface{}{hashes[i]},
+			Result: &txs[i],
+		}
+	}
+
+	if err := batchCall(ctx, s.c, reqs); err != nil {
+		return nil, err
+	}
+
+	pending := make([]*rpcTransaction, 0, len(hashes))
+	for i, tx := range txs {
+		// Transactions are dropped from the TxPool or included in a block
+		if reqs[i].Error != nil || tx == nil || tx.BlockNumber != nil || tx.From == nil {
+			s.tracked.Remove(hashes[i])
+			continue
+		}
+
+		if sender != nil && *tx.From != *sender {
+			continue
+		}
+
+		pending = append(pending, tx)
+	}
+
+	return s.classify(ctx, pending)
+}
+
+// Transaction implements mempoolSource.
+func (s *trackedSource) Transaction(
+	ctx context.Context,
+	hash common.Hash,
+) (*pooledTransaction, error) {
+	tx, err := pendingTransactionByHash(ctx, s.c, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := s.classify(ctx, []*rpcTransaction{tx})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs[0], nil
+}
+
+// classify returns the pool of txs, using the pending
+// nonce of their senders.
+func (s *trackedSource) classify(
+	ctx context.Context,
+	txs []*rpcTransaction,
+) ([]*pooledTransaction, error) {
+	var senders []common.Address
+	nonces := map[common.Address]*hexutil.Uint64{}
+	for _, tx := range txs {
+		if _, ok := nonces[*tx.From]; !ok {
+			senders = append(senders, *tx.From)
+			nonces[*tx.From] = new(hexutil.Uint64)
+		}
+	}
+
+	reqs := make([]rpc.BatchElem, len(senders))
+	for i, sender := range senders {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{sender, "pending"},
+			Result: nonces[sender],
+		}
+	}
+
+	if err := batchCall(ctx, s.c, reqs); err != nil {
+		return nil, err
+	}
+
+	for _, req := range reqs {
+		if req.Error != nil {
+			return nil, req.Error
+		}
+	}
+
+	pooled := make([]*pooledTransaction, len(txs))
+	for i, tx := range txs {
+		pool := pendingPool
+		if tx.tx.Nonce() >= uint64(*nonces[*tx.From]) {
+			pool = queuedPool
+		}
+
+		pooled[i] = &pooledTransaction{Transaction: tx, Pool: pool}
+	}
+
+	return pooled, nil
+}
+
+// Close implements mempoolSource.
+func (s *trackedSource) Close() {
+	s.subscription.Close()
+}
+
+// filterSender returns the transactions of txs sent
+// by sender, or txs if sender is nil.
+func filterSender(txs []*pooledTransaction, sender *common.Address) []*pooledTransaction {
+	if sender == nil {
+		return txs
+	}
+
+	filtered := []*pooledTransaction{}
+	for _, tx := range txs {
+		if tx.Transaction.From != nil && *tx.Transaction.From == *sender {
+			filtered = append(filtered, tx)
+		}
+	}
+
+	return filtered
+}
+
+// pendingTransactionByHash returns the transaction with hash if
+// it is not included in a block yet, or ErrTransactionNotFound.
+func pendingTransactionByHash(
+	ctx context.Context,
+	c JSONRPC,
+	hash common.Hash,
+) (*rpcTransaction, error) {
+	var tx *rpcTransaction
+	if err := c.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	}
+
+	if tx == nil || tx.BlockNumber != nil || tx.From == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	return tx, nil
+}
+
+// batchCall sends reqs in batches of at most mempoolBatchSize requests.
+func batchCall(ctx context.Context, c JSONRPC, reqs []rpc.BatchElem) error {
+	for start := 0; start < len(reqs); start += mempoolBatchSize {
+		end := start + mempoolBatchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		if err := c.BatchCallContext(ctx, reqs[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackedTransaction is a transaction tracked by trackedTransactions.
+type trackedTransaction struct {
+	Hash   common.Hash
+	Sender common.Address // zero if unknown
+}
+
+// trackedTransactions is a bounded set of transactions that may be in
+// the mempool, evicting the oldest transactions first.
+//
+// A nil *trackedTransactions tracks no transactions.
+type trackedTransactions struct {
+	mu     sync.Mutex
+	order  *list.List // of *trackedTransaction, newest first
+	byHash map[common.Hash]*list.Element
+}
+
+// newTrackedTransactions returns an empty trackedTransactions.
+func newTrackedTransactions() *trackedTransactions {
+	return &trackedTransactions{
+		order:  list.New(),
+		byHash: map[common.Hash]*list.Element{},
+	}
+}
+
+// Add tracks the transaction with hash sent by sender.
+func (t *trackedTransactions) Add(hash common.Hash, sender common.Address) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.byHash[hash]; ok {
+		return
+	}
+
+	t.byHash[hash] = t.order.PushFront(&trackedTransaction{Hash: hash, Sender: sender})
+	if t.order.Len() > trackedTransactionsSize {
+		oldest := t.order.Remove(t.order.Back()).(*trackedTransaction)
+		delete(t.byHash, oldest.Hash)
+	}
+}
+
+// Remove stops tracking the transaction with hash.
+func (t *trackedTransactions) Remove(hash common.Hash) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if elem, ok := t.byHash[hash]; ok {
+		t.order.Remove(elem)
+		delete(t.byHash, hash)
+	}
+}
+
+// Hashes returns the hashes of the tracked transactions.
+func (t *trackedTransactions) Hashes() []common.Hash {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hashes := make([]common.Hash, 0, t.order.Len())
+	for elem := t.order.Front(); elem != nil; elem = elem.Next() {
+		hashes = append(hashes, elem.Value.(*trackedTransaction).Hash)
+	}
+
+	return hashes
+}
+
+// Senders returns the known senders of the tracked transactions.
+func (t *trackedTransactions) Senders() []common.Address {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var senders []common.Address
+	seen := map[common.Address]bool{}
+	for elem := t.order.Front(); elem != nil; elem = elem.Next() {
+		sender := elem.Value.(*trackedTransaction).Sender
+		if sender == (common.Address{}) || seen[sender] {
+			continue
+		}
+
+		seen[sender] = true
+		senders = append(senders, sender)
+	}
+
+	return senders
+}
+
+// pendingTxSubscriber subscribes to the hashes of
+// the transactions entering the TxPool.
+type pendingTxSubscriber func(ctx context.Context, ch chan<- common.Hash) (ethereum.Subscription, error)
+
+// wsPendingTxSubscriber returns a pendingTxSubscriber using a
+// newPendingTransactions subscription on the WebSocket endpoint url.
+func wsPendingTxSubscriber(url string) pendingTxSubscriber {
+	return func(ctx context.Context, ch chan<- common.Hash) (ethereum.Subscription, error) {
+		c, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		sub, err := c.EthSubscribe(ctx, ch, "newPendingTransactions")
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+
+		return &closingSubscription{Subscription: sub, c: c}, nil
+	}
+}
+
+// pendingTxSubscription tracks the transactions entering the TxPool
+// in the background, resubscribing when the subscription fails.
+type pendingTxSubscription struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	subscribe pendingTxSubscriber
+	tracked   *trackedTransactions
+	hashes    chan common.Hash
+}
+
+// newPendingTxSubscription subscribes with subscribe and tracks the
+// hashes received in tracked. An error is returned if the first
+// subscription fails.
+func newPendingTxSubscription(
+	ctx context.Context,
+	subscribe pendingTxSubscriber,
+	tracked *trackedTransactions,
+) (*pendingTxSubscription, error) {
+	hashes := make(chan common.Hash, pendingTxBuffer)
+	sub, err := subscribe(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s := &pendingTxSubscription{
+		ctx:       runCtx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		subscribe: subscribe,
+		tracked:   tracked,
+		hashes:    hashes,
+	}
+	go s.run(sub)
+
+	return s, nil
+}
+
+// Close stops tracking the transactions entering the TxPool.
+func (s *pendingTxSubscription) Close() {
+	if s == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+}
+
+func (s *pendingTxSubscription) run(sub ethereum.Subscription) {
+	defer close(s.done)
+	defer func() {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+	}()
+
+	var subErr <-chan error
+	if sub != nil {
+		subErr = sub.Err()
+	}
+
+	ticker := time.NewTicker(pendingTxResubscribeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case hash := <-s.hashes:
+			s.tracked.Add(hash, common.Address{})
+		case err := <-subErr:
+			log.Printf("%v: newPendingTransactions subscription failed\n", err)
+			sub.Unsubscribe()
+			sub, subErr = nil, nil
+		case <-ticker.C:
+			if sub != nil {
+				continue
+			}
+
+			var err error
+			sub, err = s.subscribe(s.ctx, s.hashes)
+			if err != nil {
+				log.Printf("%s: unable to subscribe to newPendingTransactions\n", err.Error())
+				sub = nil
+				continue
+			}
+			subErr = sub.Err()
+		}
+	}
+}
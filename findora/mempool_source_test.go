@@ -0,0 +1,318 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testPoolTransaction returns a transaction with nonce
+// as returned by the node for sender.
+func testPoolTransaction(sender common.Address, nonce uint64) rpcTransaction {
+	return rpcTransaction{
+		tx:          types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)}),
+		txExtraInfo: txExtraInfo{From: &sender},
+	}
+}
+
+func TestDetectMempoolSource(t *testing.T) {
+	unsupported := errors.New("the method does not exist/is not available")
+
+	t.Run("txpool_content", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "txpool_content").Return(nil).Once()
+		mockJSONRPC.On(
+			"CallContext", mock.Anything, mock.Anything, "txpool_contentFrom", common.Address{},
+		).Return(unsupported).Once()
+
+		source := detectMempoolSource(mockJSONRPC, "", newTrackedTransactions())
+		assert.Equal(t, &txPoolContentSource{c: mockJSONRPC, contentFrom: false}, source)
+		mockJSONRPC.AssertExpectations(t)
+	})
+
+	t.Run("txpool_contentFrom", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "txpool_content").Return(unsupported).Once()
+		mockJSONRPC.On(
+			"CallContext", mock.Anything, mock.Anything, "txpool_contentFrom", common.Address{},
+		).Return(nil).Once()
+
+		source := detectMempoolSource(mockJSONRPC, "", newTrackedTransactions())
+		assert.IsType(t, &txPoolContentFromSource{}, source)
+		mockJSONRPC.AssertExpectations(t)
+	})
+
+	t.Run("submitted", func(t *testing.T) {
+		mockJSONRPC := &mocks.JSONRPC{}
+		mockJSONRPC.On("CallContext", mock.Anything, mock.Anything, "txpool_content").Return(unsupported).Once()
+		mockJSONRPC.On(
+			"CallContext", mock.Anything, mock.Anything, "txpool_contentFrom", common.Address{},
+		).Return(unsupported).Once()
+
+		source := detectMempoolSource(mockJSONRPC, "", newTrackedTransactions())
+		assert.IsType(t, &trackedSource{}, source)
+		assert.Nil(t, source.(*trackedSource).subscription)
+		mockJSONRPC.AssertExpectations(t)
+	})
+}
+
+func TestTxPoolContentFromSource(t *testing.T) {
+	sender := common.HexToAddress("0x4DE23f3f0Fb3318287378AdbdE030cf61714b2f3")
+	pending := testPoolTransaction(sender, 1)
+	queued := testPoolTransaction(sender, 3)
+	included := testPoolTransaction(sender, 0)
+
+	submitted := newTrackedTransactions()
+	submitted.Add(included.tx.Hash(), sender)
+	submitted.Add(pending.tx.Hash(), sender)
+
+	response := txPoolContentFromResponse{
+		Pending: txPoolInner{"1": pending},
+		Queued:  txPoolInner{"3": queued},
+	}
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+
+			assert.Len(t, r, 1)
+			assert.Equal(t, "txpool_contentFrom", r[0].Method)
+			assert.Equal(t, []interface{}{sender}, r[0].Args)
+			*(r[0].Result.(*txPoolContentFromResponse)) = response
+		},
+	).Once()
+
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "eth_getTransactionByHash", queued.tx.Hash(),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			tx := queued
+			*args.Get(1).(**rpcTransaction) = &tx
+		},
+	).Once()
+
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "txpool_contentFrom", sender,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*txPoolContentFromResponse) = response
+		},
+	).Once()
+
+	source := &txPoolContentFromSource{c: mockJSONRPC, submitted: submitted}
+	ctx := context.Background()
+
+	txs, err := source.Transactions(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[common.Hash]string{
+		pending.tx.Hash(): pendingPool,
+		queued.tx.Hash():  queuedPool,
+	}, testPools(txs))
+
+	// The included transaction is not tracked anymore
+	assert.Equal(t, []common.Hash{pending.tx.Hash()}, submitted.Hashes())
+
+	tx, err := source.Transaction(ctx, queued.tx.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, queued.tx.Hash(), tx.Transaction.tx.Hash())
+	assert.Equal(t, queuedPool, tx.Pool)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestTrackedSource(t *testing.T) {
+	sender := common.HexToAddress("0x4DE23f3f0Fb3318287378AdbdE030cf61714b2f3")
+	pending := testPoolTransaction(sender, 1)
+	queued := testPoolTransaction(sender, 5)
+	included := testPoolTransaction(sender, 0)
+	included.BlockNumber = new(string)
+	*included.BlockNumber = "0x10"
+	dropped := testPoolTransaction(sender, 2)
+
+	tracked := newTrackedTransactions()
+	for _, tx := range []rpcTransaction{included, pending, dropped, queued} {
+		tracked.Add(tx.tx.Hash(), common.Address{})
+	}
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			for _, req := range args.Get(1).([]rpc.BatchElem) {
+				if req.Method == "eth_getTransactionCount" {
+					assert.Equal(t, []interface{}{sender, "pending"}, req.Args)
+					*(req.Result.(*hexutil.Uint64)) = 2
+					continue
+				}
+
+				assert.Equal(t, "eth_getTransactionByHash", req.Method)
+				for _, tx := range []rpcTransaction{pending, queued, included} {
+					if req.Args[0] == tx.tx.Hash() {
+						tx := tx
+						*(req.Result.(**rpcTransaction)) = &tx
+					}
+				}
+			}
+		},
+	)
+
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "eth_getTransactionByHash", included.tx.Hash(),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			tx := included
+			*args.Get(1).(**rpcTransaction) = &tx
+		},
+	).Once()
+
+	source := &trackedSource{c: mockJSONRPC, tracked: tracked}
+	ctx := context.Background()
+
+	// Transactions are queued if their nonce is
+	// not below the pending nonce of their sender
+	txs, err := source.Transactions(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[common.Hash]string{
+		pending.tx.Hash(): pendingPool,
+		queued.tx.Hash():  queuedPool,
+	}, testPools(txs))
+	assert.ElementsMatch(t, []common.Hash{pending.tx.Hash(), queued.tx.Hash()}, tracked.Hashes())
+
+	other := common.HexToAddress("0x0297215e64d312d3a239995345e574f73ef59b02")
+	txs, err = source.Transactions(ctx, &other)
+	assert.NoError(t, err)
+	assert.Empty(t, txs)
+
+	tx, err := source.Transaction(ctx, included.tx.Hash())
+	assert.Nil(t, tx)
+	assert.True(t, errors.Is(err, ErrTransactionNotFound))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+// testPools returns the pool of txs by hash.
+func testPools(txs []*pooledTransaction) map[common.Hash]string {
+	pools := map[common.Hash]string{}
+	for _, tx := range txs {
+		pools[tx.Transaction.tx.Hash()] = tx.Pool
+	}
+
+	return pools
+}
+
+func TestTrackedTransactions(t *testing.T) {
+	tracked := newTrackedTransactions()
+	for i := 0; i < trackedTransactionsSize+1; i++ {
+		hash := common.HexToHash(fmt.Sprintf("%x", i+1))
+		tracked.Add(hash, common.BigToAddress(big.NewInt(int64(i%2))))
+	}
+
+	// The oldest transaction is evicted
+	hashes := tracked.Hashes()
+	assert.Len(t, hashes, trackedTransactionsSize)
+	assert.Equal(t, common.HexToHash(fmt.Sprintf("%x", trackedTransactionsSize+1)), hashes[0])
+	assert.Equal(t, common.HexToHash("2"), hashes[len(hashes)-1])
+
+	// Unknown senders are not returned
+	assert.Equal(t, []common.Address{common.BigToAddress(big.NewInt(1))}, tracked.Senders())
+
+	tracked.Remove(common.HexToHash("2"))
+	assert.Len(t, tracked.Hashes(), trackedTransactionsSize-1)
+}
+
+func TestPendingTxSubscription(t *testing.T) {
+	hashes := make(chan chan<- common.Hash, 1)
+	subscribe := func(ctx context.Context, ch chan<- common.Hash) (ethereum.Subscription, error) {
+		hashes <- ch
+		return &testSubscription{err: make(chan error)}, nil
+	}
+
+	tracked := newTrackedTransactions()
+	subscription, err := newPendingTxSubscription(context.Background(), subscribe, tracked)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	hash := common.HexToHash("0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4")
+	(<-hashes) <- hash
+	assert.Eventually(t, func() bool {
+		return len(tracked.Hashes()) == 1 && tracked.Hashes()[0] == hash
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPendingTxSubscription_Unavailable(t *testing.T) {
+	subscribe := func(ctx context.Context, ch chan<- common.Hash) (ethereum.Subscription, error) {
+		return nil, errors.New("notifications not supported")
+	}
+
+	subscription, err := newPendingTxSubscription(context.Background(), subscribe, newTrackedTransactions())
+	assert.Nil(t, subscription)
+	assert.Error(t, err)
+}
+
+func TestSendTransaction_Tracked(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	signer := types.LatestSignerForChainID(big.NewInt(2152))
+	tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1)})
+	assert.NoError(t, err)
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, nil, "eth_sendRawTransaction", mock.Anything,
+	).Return(nil).Once()
+
+	c := &Client{c: mockJSONRPC, submitted: newTrackedTransactions()}
+	assert.NoError(t, c.SendTransaction(context.Background(), tx))
+
+	assert.Equal(t, []common.Hash{tx.Hash()}, c.submitted.Hashes())
+	assert.Equal(t, []common.Address{crypto.PubkeyToAddress(key.PublicKey)}, c.submitted.Senders())
+
+	mockJSONRPC.AssertExpectations(t)
+}
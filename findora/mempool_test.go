@@ -0,0 +1,289 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockTxPoolContent makes mockJSONRPC return the
+// test txpool_content and a header with baseFee.
+// testMempoolMiner is the proposer of the latest
+// block returned by mockTxPoolContent.
+const testMempoolMiner = "0x4Cfc400fed52F9681b42454C2DB4b18aB98F8de1"
+
+func mockTxPoolContent(t *testing.T, mockJSONRPC *mocks.JSONRPC, baseFee *big.Int) {
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "txpool_content",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			file, err := ioutil.ReadFile("testdata/txpool_content.json")
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(file, args.Get(1)))
+		},
+	).Once()
+
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "eth_getBlockByNumber", "latest", false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(**types.Header) = &types.Header{
+				Number:     big.NewInt(100),
+				Difficulty: big.NewInt(0),
+				BaseFee:    baseFee,
+				Coinbase:   common.HexToAddress(testMempoolMiner),
+			}
+		},
+	).Maybe()
+}
+
+// mockPendingNonce makes mockJSONRPC return nonce
+// as the pending nonce of sender.
+func mockPendingNonce(mockJSONRPC *mocks.JSONRPC, sender common.Address, nonce uint64) {
+	mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "eth_getTransactionCount", sender, "pending",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*hexutil.Uint64) = hexutil.Uint64(nonce)
+		},
+	).Once()
+}
+
+func TestGetMempool_Filter(t *testing.T) {
+	sender := "0x4DE23f3f0Fb3318287378AdbdE030cf61714b2f3"
+	tests := map[string]struct {
+		filter map[string]interface{}
+		hashes []string
+	}{
+		"sender": {
+			filter: map[string]interface{}{"address": sender},
+			hashes: []string{
+				"0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb",
+				"0xb39652e66c57a6693e44b92b5c471952c26cabf9442a9a76c372f8690658cb4c",
+				"0x1e8700bf7215b2da0cfadcf34717f387577254e0871d828e5453a0593ce8060f",
+				"0x83811383fb7840a03c25a7cbae7e9af138b17853563eb9e212727be2d0b9667f",
+			},
+		},
+		"sender and min gas price": {
+			filter: map[string]interface{}{
+				"address":       sender,
+				"pool":          "queued",
+				"min_gas_price": "0x13f6b91c76",
+			},
+			hashes: []string{
+				"0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb",
+				"0xb39652e66c57a6693e44b92b5c471952c26cabf9442a9a76c372f8690658cb4c",
+			},
+		},
+		"sender pending": {
+			filter: map[string]interface{}{"address": sender, "pool": "pending"},
+			hashes: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			mockTxPoolContent(t, mockJSONRPC, big.NewInt(1000))
+			c := &Client{c: mockJSONRPC}
+
+			resp, err := c.GetMempool(context.Background(), test.filter)
+			assert.NoError(t, err)
+
+			hashes := []string{}
+			for _, identifier := range resp.TransactionIdentifiers {
+				hashes = append(hashes, identifier.Hash)
+			}
+			assert.ElementsMatch(t, test.hashes, hashes)
+
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMempool_InvalidFilter(t *testing.T) {
+	c := &Client{c: &mocks.JSONRPC{}}
+
+	for _, filter := range []map[string]interface{}{
+		{"address": "hello"},
+		{"pool": "future"},
+		{"min_gas_price": "cheap"},
+		{"min_gas_price": "-1"},
+		{"pool": 1},
+	} {
+		resp, err := c.GetMempool(context.Background(), filter)
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrInvalidMempoolFilter))
+	}
+}
+
+func TestGetMempoolTransaction(t *testing.T) {
+	tests := map[string]struct {
+		hash     string
+		from     string
+		to       string
+		value    string
+		tip      string
+		pool     string
+		nonce    string
+		gasPrice string
+		nonceGap map[string]interface{}
+	}{
+		"pending": {
+			hash:     "0x994024ef9f05d1cb25d01572642c1f550c78d214a52c306bb100d22c025b59d4",
+			from:     "0x0297215e64d312d3A239995345E574F73Ef59B02",
+			to:       "0x6efF3372fa352b239Bb24ff91b423A572347000D",
+			value:    "2176430000000000",
+			tip:      "839999979000000",
+			pool:     "pending",
+			nonce:    "0x3",
+			gasPrice: "0x9502f9000",
+		},
+		"queued": {
+			hash:     "0xda591f0b15423aedb52f6b0e778b1fbc6757547d69277e2ba1aa7093583d1efb",
+			from:     "0x4DE23f3f0Fb3318287378AdbdE030cf61714b2f3",
+			to:       "0xbdDd1BA49F4426f2830736e0DB46cC8180F5b9d4",
+			value:    "6349136062952123",
+			tip:      "1849233833076000",
+			pool:     "queued",
+			nonce:    "0xea",
+			gasPrice: "0x1480b5f78c",
+			nonceGap: map[string]interface{}{
+				"pending_nonce":  "0xe6",
+				"missing_nonces": "0x4",
+				"explanation":    "waiting for the transactions with nonces 230 to 233 of the sender",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			mockTxPoolContent(t, mockJSONRPC, big.NewInt(1000))
+			if test.nonceGap != nil {
+				mockPendingNonce(mockJSONRPC, common.HexToAddress(test.from), 230)
+			}
+			c := &Client{c: mockJSONRPC, feePolicy: &FindoraFeePolicy{}}
+
+			resp, err := c.GetMempoolTransaction(
+				context.Background(),
+				&RosettaTypes.TransactionIdentifier{Hash: test.hash},
+			)
+			assert.NoError(t, err)
+
+			tx := resp.Transaction
+			assert.Equal(t, test.hash, tx.TransactionIdentifier.Hash)
+			// The base fee of 1000 is burned for each of the 21000 gas
+			assert.Equal(t, []*RosettaTypes.Operation{
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+					Type:                FeeOpType,
+					Account:             &RosettaTypes.AccountIdentifier{Address: test.from},
+					Amount:              &RosettaTypes.Amount{Value: "-" + test.tip, Currency: Currency},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+					RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 0}},
+					Type:                FeeTipOpType,
+					Account:             &RosettaTypes.AccountIdentifier{Address: testMempoolMiner},
+					Amount:              &RosettaTypes.Amount{Value: test.tip, Currency: Currency},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 2},
+					Type:                FeeBurnOpType,
+					Account:             &RosettaTypes.AccountIdentifier{Address: test.from},
+					Amount:              &RosettaTypes.Amount{Value: "-21000000", Currency: Currency},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 3},
+					Type:                CallOpType,
+					Account:             &RosettaTypes.AccountIdentifier{Address: test.from},
+					Amount:              &RosettaTypes.Amount{Value: "-" + test.value, Currency: Currency},
+				},
+				{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 4},
+					RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 3}},
+					Type:                CallOpType,
+					Account:             &RosettaTypes.AccountIdentifier{Address: test.to},
+					Amount:              &RosettaTypes.Amount{Value: test.value, Currency: Currency},
+				},
+			}, tx.Operations)
+			assert.Equal(t, test.pool, tx.Metadata["pool"])
+			assert.Equal(t, test.nonce, tx.Metadata["nonce"])
+			assert.Equal(t, test.gasPrice, tx.Metadata["gas_price"])
+			assert.Equal(t, test.gasPrice, tx.Metadata["effective_gas_price"])
+			if test.nonceGap != nil {
+				assert.Equal(t, test.nonceGap, tx.Metadata["nonce_gap"])
+			} else {
+				assert.NotContains(t, tx.Metadata, "nonce_gap")
+			}
+
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMempoolTransaction_NotFound(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTxPoolContent(t, mockJSONRPC, nil)
+	c := &Client{c: mockJSONRPC}
+
+	resp, err := c.GetMempoolTransaction(
+		context.Background(),
+		&RosettaTypes.TransactionIdentifier{
+			Hash: "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4",
+		},
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrTransactionNotFound))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestPendingFee(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Gas:       100,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(20),
+	})
+
+	// The fee cap is paid without a base fee or if the
+	// base fee is too high
+	assert.Equal(t, big.NewInt(2000), pendingFee(tx, nil))
+	assert.Equal(t, big.NewInt(2000), pendingFee(tx, big.NewInt(30)))
+
+	assert.Equal(t, big.NewInt(1200), pendingFee(tx, big.NewInt(10)))
+	assert.Equal(t, big.NewInt(2000), pendingFee(tx, big.NewInt(19)))
+}
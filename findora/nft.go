@@ -0,0 +1,159 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+)
+
+const (
+	// erc721TransferTopicCount is the number of topics of an
+	// ERC-721 Transfer log (the token id is indexed).
+	erc721TransferTopicCount = 4
+
+	// erc1155TransferTopicCount is the number of topics of an ERC-1155
+	// TransferSingle or TransferBatch log (operator, from and to are indexed).
+	erc1155TransferTopicCount = 4
+)
+
+var (
+	// transferSingleEventTopic is the topic of the
+	// TransferSingle(address,address,address,uint256,uint256) event.
+	transferSingleEventTopic = crypto.Keccak256Hash(
+		[]byte("TransferSingle(address,address,address,uint256,uint256)"),
+	)
+
+	// transferBatchEventTopic is the topic of the
+	// TransferBatch(address,address,address,uint256[],uint256[]) event.
+	transferBatchEventTopic = crypto.Keccak256Hash(
+		[]byte("TransferBatch(address,address,address,uint256[],uint256[])"),
+	)
+
+	// transferBatchArguments are the non-indexed
+	// arguments of the TransferBatch event.
+	transferBatchArguments = func() abi.Arguments {
+		uint256Array, err := abi.NewType("uint256[]", "", nil)
+		if err != nil {
+			panic(err)
+		}
+
+		return abi.Arguments{{Type: uint256Array}, {Type: uint256Array}}
+	}()
+)
+
+// nftCurrency returns the *RosettaTypes.Currency of the non-fungible
+// token tokenID of contract. Every token id is a different currency
+// with 0 decimals. The symbol is taken from the token registry, if any.
+func (ec *Client) nftCurrency(contract common.Address, tokenID *big.Int) *RosettaTypes.Currency {
+	symbol := UnknownTokenSymbol
+	if token, ok := ec.tokens.Token(contract); ok {
+		symbol = token.Symbol
+	}
+
+	return &RosettaTypes.Currency{
+		Symbol:   symbol,
+		Decimals: 0,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: MustChecksum(contract.Hex()),
+			TokenIDKey:         tokenID.String(),
+		},
+	}
+}
+
+// decodeERC721Transfer decodes log if it is an ERC-721 Transfer log.
+// It shares its signature with the ERC-20 Transfer event but
+// indexes the token id instead of logging a value.
+func decodeERC721Transfer(log *types.Log) (*tokenTransfer, bool) {
+	if len(log.Topics) != erc721TransferTopicCount || log.Topics[0] != transferEventTopic {
+		return nil, false
+	}
+
+	if len(log.Data) != 0 {
+		return nil, false
+	}
+
+	return &tokenTransfer{
+		OpType:   ERC721TransferOpType,
+		Contract: log.Address,
+		From:     common.BytesToAddress(log.Topics[1].Bytes()),
+		To:       common.BytesToAddress(log.Topics[2].Bytes()),
+		Value:    big.NewInt(1),
+		LogIndex: log.Index,
+		TokenID:  log.Topics[3].Big(),
+	}, true
+}
+
+// decodeERC1155Transfers decodes log if it is an ERC-1155
+// TransferSingle or TransferBatch log. A TransferBatch log
+// is decoded into one transfer per token id.
+func decodeERC1155Transfers(log *types.Log) []*tokenTransfer {
+	if len(log.Topics) != erc1155TransferTopicCount {
+		return nil
+	}
+
+	var ids, values []*big.Int
+	switch log.Topics[0] {
+	case transferSingleEventTopic:
+		if len(log.Data) != 2*uint256Size {
+			return nil
+		}
+
+		ids = []*big.Int{new(big.Int).SetBytes(log.Data[:uint256Size])}
+		values = []*big.Int{new(big.Int).SetBytes(log.Data[uint256Size:])}
+	case transferBatchEventTopic:
+		unpacked, err := transferBatchArguments.Unpack(log.Data)
+		if err != nil {
+			return nil
+		}
+
+		ids, _ = unpacked[0].([]*big.Int)
+		values, _ = unpacked[1].([]*big.Int)
+		if len(ids) != len(values) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	operator := common.BytesToAddress(log.Topics[1].Bytes())
+	transfers := make([]*tokenTransfer, len(ids))
+	for i := range ids {
+		metadata := map[string]interface{}{
+			"operator": MustChecksum(operator.Hex()),
+		}
+		if log.Topics[0] == transferBatchEventTopic {
+			metadata["batch_index"] = i
+		}
+
+		transfers[i] = &tokenTransfer{
+			OpType:   ERC1155TransferOpType,
+			Contract: log.Address,
+			From:     common.BytesToAddress(log.Topics[2].Bytes()),
+			To:       common.BytesToAddress(log.Topics[3].Bytes()),
+			Value:    values[i],
+			LogIndex: log.Index,
+			TokenID:  ids[i],
+			Metadata: metadata,
+		}
+	}
+
+	return transfers
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testOperator = common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+func testERC721TransferLog(from, to common.Address, tokenID int64, index uint) *types.Log {
+	return &types.Log{
+		Address: testTokenContract,
+		Topics: []common.Hash{
+			transferEventTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+			common.BigToHash(big.NewInt(tokenID)),
+		},
+		Index: index,
+	}
+}
+
+func testERC1155Topics(topic common.Hash, from, to common.Address) []common.Hash {
+	return []common.Hash{
+		topic,
+		common.BytesToHash(testOperator.Bytes()),
+		common.BytesToHash(from.Bytes()),
+		common.BytesToHash(to.Bytes()),
+	}
+}
+
+func testNFTCurrency(tokenID string) *RosettaTypes.Currency {
+	return &RosettaTypes.Currency{
+		Symbol:   UnknownTokenSymbol,
+		Decimals: 0,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: MustChecksum(testTokenContract.Hex()),
+			TokenIDKey:         tokenID,
+		},
+	}
+}
+
+func TestNFTTransferOps(t *testing.T) {
+	c := &Client{}
+
+	batchData, err := transferBatchArguments.Pack(
+		[]*big.Int{big.NewInt(7), big.NewInt(8)},
+		[]*big.Int{big.NewInt(3), big.NewInt(4)},
+	)
+	assert.NoError(t, err)
+
+	tx := &loadedTransaction{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				testERC721TransferLog(common.Address{}, testTokenTo, 42, 0), // mint
+				{
+					Address: testTokenContract,
+					Topics:  testERC1155Topics(transferSingleEventTopic, testTokenFrom, testTokenTo),
+					Data: append(
+						common.LeftPadBytes(big.NewInt(5).Bytes(), uint256Size),
+						common.LeftPadBytes(big.NewInt(10).Bytes(), uint256Size)...,
+					),
+					Index: 1,
+				},
+				{
+					Address: testTokenContract,
+					Topics:  testERC1155Topics(transferBatchEventTopic, testTokenFrom, common.Address{}),
+					Data:    batchData,
+					Index:   2,
+				},
+				{
+					// Malformed logs are ignored
+					Address: testTokenContract,
+					Topics:  testERC1155Topics(transferBatchEventTopic, testTokenFrom, testTokenTo),
+					Data:    []byte{0x1},
+					Index:   3,
+				},
+			},
+		},
+	}
+
+	ops, err := c.tokenTransferOps(context.Background(), tx, 1)
+	assert.NoError(t, err)
+
+	operator := MustChecksum(testOperator.Hex())
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+			Type:                ERC721TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenTo.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "1", Currency: testNFTCurrency("42")},
+			Metadata:            map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 2},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenFrom.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-10", Currency: testNFTCurrency("5")},
+			Metadata:            map[string]interface{}{"log_index": uint(1), "operator": operator},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 3},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 2}},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenTo.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "10", Currency: testNFTCurrency("5")},
+			Metadata:            map[string]interface{}{"log_index": uint(1), "operator": operator},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 4},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenFrom.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-3", Currency: testNFTCurrency("7")},
+			Metadata: map[string]interface{}{
+				"log_index":   uint(2),
+				"operator":    operator,
+				"batch_index": 0,
+			},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 5},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenFrom.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-4", Currency: testNFTCurrency("8")},
+			Metadata: map[string]interface{}{
+				"log_index":   uint(2),
+				"operator":    operator,
+				"batch_index": 1,
+			},
+		},
+	}, ops)
+
+	// Balances of non-fungible tokens are not supported
+	_, err = c.tokenContract(testNFTCurrency("42"))
+	assert.ErrorIs(t, err, ErrCurrencyNotSupported)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PendingBalance is the pending state of an account, next to
+// its confirmed balance. Amounts are in the smallest unit of FRA.
+type PendingBalance struct {
+	// Nonce is the nonce of the next transaction of the
+	// account, counting its executable pending transactions.
+	Nonce int64 `json:"nonce"`
+
+	// Balance is the balance of the account in the pending
+	// state of the node.
+	Balance string `json:"balance"`
+
+	// MempoolTransactions is the number of transactions of the
+	// account in the mempool (pending and queued).
+	MempoolTransactions int `json:"mempool_transactions"`
+
+	// MempoolValue is the value sent by these transactions.
+	MempoolValue string `json:"mempool_value"`
+
+	// MempoolFees is the highest fee these transactions can
+	// pay for the base fee of the latest block.
+	MempoolFees string `json:"mempool_fees"`
+
+	// Available is the confirmed balance less the value and
+	// the fees of these transactions (0 if they exceed it).
+	Available string `json:"available"`
+}
+
+// pendingBalance returns the pending state of address, whose
+// confirmed balance is confirmed.
+func (ec *Client) pendingBalance(
+	ctx context.Context,
+	address common.Address,
+	confirmed *big.Int,
+) (*PendingBalance, error) {
+	nonce, err := ec.PendingNonceAt(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get pending nonce", err)
+	}
+
+	var balance hexutil.Big
+	if err := ec.c.CallContext(ctx, &balance, "eth_getBalance", address, "pending"); err != nil {
+		return nil, fmt.Errorf("%w: could not get pending balance", err)
+	}
+
+	txs, err := ec.mempoolSource().Transactions(ctx, &address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get mempool transactions", err)
+	}
+
+	head, err := ec.blockHeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get latest header", err)
+	}
+
+	value, fees := new(big.Int), new(big.Int)
+	for _, tx := range txs {
+		value.Add(value, tx.Transaction.tx.Value())
+		fees.Add(fees, pendingFee(tx.Transaction.tx, head.BaseFee))
+	}
+
+	available := new(big.Int).Sub(confirmed, value)
+	available.Sub(available, fees)
+	if available.Sign() < 0 {
+		available.SetInt64(0)
+	}
+
+	return &PendingBalance{
+		Nonce:               int64(nonce),
+		Balance:             balance.ToInt().String(),
+		MempoolTransactions: len(txs),
+		MempoolValue:        value.String(),
+		MempoolFees:         fees.String(),
+		Available:           available.String(),
+	}, nil
+}
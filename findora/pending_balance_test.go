@@ -0,0 +1,153 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testMempoolSource is a mempoolSource returning txs.
+type testMempoolSource struct {
+	txs []*pooledTransaction
+}
+
+func (s *testMempoolSource) Transactions(
+	ctx context.Context,
+	sender *common.Address,
+) ([]*pooledTransaction, error) {
+	return filterSender(s.txs, sender), nil
+}
+
+func (s *testMempoolSource) Transaction(
+	ctx context.Context,
+	hash common.Hash,
+) (*pooledTransaction, error) {
+	return nil, ErrTransactionNotFound
+}
+
+func (s *testMempoolSource) Close() {}
+
+func TestPendingBalance(t *testing.T) {
+	address := common.HexToAddress(testBalanceAccount)
+	other := common.HexToAddress("0x0297215e64d312d3a239995345e574f73ef59b02")
+	pooled := func(from common.Address, pool string, tx types.TxData) *pooledTransaction {
+		return &pooledTransaction{
+			Transaction: &rpcTransaction{tx: types.NewTx(tx), txExtraInfo: txExtraInfo{From: &from}},
+			Pool:        pool,
+		}
+	}
+
+	tests := map[string]struct {
+		confirmed int64
+		expected  *PendingBalance
+	}{
+		"available": {
+			confirmed: 10000,
+			expected: &PendingBalance{
+				Nonce:               8,
+				Balance:             "9000",
+				MempoolTransactions: 2,
+				MempoolValue:        "1500",
+				MempoolFees:         "1300", // 100 * 3 + 100 * (5 + 5)
+				Available:           "7200",
+			},
+		},
+		"overspent": {
+			confirmed: 2000,
+			expected: &PendingBalance{
+				Nonce:               8,
+				Balance:             "9000",
+				MempoolTransactions: 2,
+				MempoolValue:        "1500",
+				MempoolFees:         "1300",
+				Available:           "0",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			mockPendingNonce(mockJSONRPC, address, 8)
+			mockJSONRPC.On(
+				"CallContext", mock.Anything, mock.Anything, "eth_getBalance", address, "pending",
+			).Return(
+				nil,
+			).Run(
+				func(args mock.Arguments) {
+					*args.Get(1).(*hexutil.Big) = hexutil.Big(*big.NewInt(9000))
+				},
+			).Once()
+			mockTimestampChain(mockJSONRPC, []*types.Header{
+				{Number: big.NewInt(0), Difficulty: big.NewInt(0), BaseFee: big.NewInt(5)},
+			})
+
+			c := &Client{
+				c: mockJSONRPC,
+				mempool: &testMempoolSource{txs: []*pooledTransaction{
+					pooled(address, pendingPool, &types.LegacyTx{
+						Nonce: 7, Gas: 100, GasPrice: big.NewInt(3), Value: big.NewInt(1000),
+					}),
+					pooled(address, queuedPool, &types.DynamicFeeTx{
+						Nonce: 9, Gas: 100, GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(20),
+						Value: big.NewInt(500),
+					}),
+					pooled(other, pendingPool, &types.LegacyTx{
+						Nonce: 1, Gas: 100, GasPrice: big.NewInt(3), Value: big.NewInt(1000),
+					}),
+				}},
+			}
+
+			pending, err := c.pendingBalance(context.Background(), address, big.NewInt(test.confirmed))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, pending)
+
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBalance_PendingWithBlock(t *testing.T) {
+	c := &Client{c: &mocks.JSONRPC{}}
+
+	for _, metadata := range []map[string]interface{}{
+		{"include_pending": true, "timestamp": float64(1420000)},
+		{"include_pending": true},
+	} {
+		resp, err := c.Balance(
+			context.Background(),
+			&RosettaTypes.AccountIdentifier{
+				Address:  testBalanceAccount,
+				Metadata: metadata,
+			},
+			&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(42)},
+			nil,
+		)
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrInvalidBalanceOptions))
+	}
+}
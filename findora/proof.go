@@ -0,0 +1,155 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// emptyCodeHash is the code hash of accounts without code.
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// AccountProof is the Merkle proof of the state of an
+// account returned by eth_getProof (EIP-1186).
+type AccountProof struct {
+	Address      common.Address    `json:"address"`
+	AccountProof []hexutil.Bytes   `json:"accountProof"`
+	Balance      *hexutil.Big      `json:"balance"`
+	CodeHash     common.Hash       `json:"codeHash"`
+	Nonce        hexutil.Uint64    `json:"nonce"`
+	StorageHash  common.Hash       `json:"storageHash"`
+	StorageProof []json.RawMessage `json:"storageProof"`
+
+	// Verified is true if the proof was verified against the
+	// state root of its block. Error is why it was not.
+	Verified bool   `json:"-"`
+	Error    string `json:"-"`
+}
+
+// accountProof returns the proof of the state of address at the block
+// of state. The proof is returned even if it could not be verified
+// against the state root of the block or does not prove state, in
+// which case Verified is false and Error says why.
+func (ec *Client) accountProof(
+	ctx context.Context,
+	address common.Address,
+	state *accountState,
+) (*AccountProof, error) {
+	blockHash := common.HexToHash(state.Block.Hash)
+
+	proof := new(AccountProof)
+	err := ec.c.CallContext(ctx, proof, "eth_getProof", address, []string{}, toBlockHashArg(blockHash))
+	if err != nil {
+		return nil, checkCanonical(err, blockHash)
+	}
+
+	head, err := ec.blockHeaderByHash(ctx, blockHash.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get header of %s", err, blockHash.Hex())
+	}
+	if head.Hash() != blockHash {
+		return nil, fmt.Errorf(
+			"expected header hash %s but got %s",
+			blockHash.Hex(),
+			head.Hash().Hex(),
+		)
+	}
+
+	if err := verifyAccountProof(head.Root, address, proof, state); err != nil {
+		proof.Error = err.Error()
+	} else {
+		proof.Verified = true
+	}
+
+	return proof, nil
+}
+
+// verifyAccountProof verifies that proof proves the state of address
+// in the state trie with root, and that it matches state.
+func verifyAccountProof(
+	root common.Hash,
+	address common.Address,
+	proof *AccountProof,
+	state *accountState,
+) error {
+	if proof.Address != address {
+		return fmt.Errorf("proof is for %s", proof.Address.Hex())
+	}
+	if proof.Balance == nil {
+		return errors.New("proof has no balance")
+	}
+
+	proofDB := memorydb.New()
+	for _, node := range proof.AccountProof {
+		if err := proofDB.Put(crypto.Keccak256(node), node); err != nil {
+			return err
+		}
+	}
+
+	value, err := trie.VerifyProof(root, crypto.Keccak256(address.Bytes()), proofDB)
+	if err != nil {
+		return fmt.Errorf("%w: invalid account proof", err)
+	}
+
+	// Accounts that are not in the state trie are empty
+	account := types.StateAccount{
+		Balance:  new(big.Int),
+		Root:     types.EmptyRootHash,
+		CodeHash: emptyCodeHash.Bytes(),
+	}
+	if value != nil {
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			return fmt.Errorf("%w: invalid account in proof", err)
+		}
+	}
+
+	switch {
+	case account.Balance.Cmp(proof.Balance.ToInt()) != 0:
+		return fmt.Errorf("proven balance is %s but proof has %s", account.Balance, proof.Balance.ToInt())
+	case account.Nonce != uint64(proof.Nonce):
+		return fmt.Errorf("proven nonce is %d but proof has %d", account.Nonce, proof.Nonce)
+	case !bytes.Equal(account.CodeHash, proof.CodeHash.Bytes()):
+		return fmt.Errorf("proven code hash is %x but proof has %s", account.CodeHash, proof.CodeHash.Hex())
+	case account.Root != proof.StorageHash:
+		return fmt.Errorf("proven storage hash is %s but proof has %s", account.Root.Hex(), proof.StorageHash.Hex())
+	case account.Balance.Cmp(state.Balance) != 0:
+		return fmt.Errorf("proven balance is %s but balance is %s", account.Balance, state.Balance)
+	case account.Nonce != state.Nonce:
+		return fmt.Errorf("proven nonce is %d but nonce is %d", account.Nonce, state.Nonce)
+	}
+
+	code, err := hexutil.Decode(state.Code)
+	if err != nil {
+		return fmt.Errorf("%w: invalid code %s", err, state.Code)
+	}
+	if !bytes.Equal(account.CodeHash, crypto.Keccak256(code)) {
+		return fmt.Errorf("proven code hash is %x but code hash is %x", account.CodeHash, crypto.Keccak256(code))
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testStateTrie returns a state trie with an account for each address
+// (with balance i+1 and nonce i) and the proof of each account.
+func testStateTrie(t *testing.T, addresses ...common.Address) (common.Hash, map[common.Address]*AccountProof) {
+	stateTrie := trie.NewEmpty(trie.NewDatabase(memorydb.New()))
+	accounts := map[common.Address]*types.StateAccount{}
+	for i, address := range addresses {
+		accounts[address] = &types.StateAccount{
+			Nonce:    uint64(i),
+			Balance:  big.NewInt(int64(i + 1)),
+			Root:     types.EmptyRootHash,
+			CodeHash: emptyCodeHash.Bytes(),
+		}
+		assert.NoError(t, stateTrie.TryUpdateAccount(crypto.Keccak256(address.Bytes()), accounts[address]))
+	}
+	root := stateTrie.Hash()
+
+	proofs := map[common.Address]*AccountProof{}
+	for address, account := range accounts {
+		proofDB := memorydb.New()
+		assert.NoError(t, stateTrie.Prove(crypto.Keccak256(address.Bytes()), 0, proofDB))
+
+		proof := &AccountProof{
+			Address:     address,
+			Balance:     (*hexutil.Big)(account.Balance),
+			CodeHash:    emptyCodeHash,
+			Nonce:       hexutil.Uint64(account.Nonce),
+			StorageHash: types.EmptyRootHash,
+		}
+		it := proofDB.NewIterator(nil, nil)
+		for it.Next() {
+			proof.AccountProof = append(proof.AccountProof, common.CopyBytes(it.Value()))
+		}
+		it.Release()
+
+		proofs[address] = proof
+	}
+
+	return root, proofs
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	alice := common.HexToAddress("0x2f93B2f047E05cdf602820Ac4B3178efc2b43D55")
+	bob := common.HexToAddress("0x4cfc400fed52f9681b42454c2db4b18ab98f8de1")
+	root, proofs := testStateTrie(t, alice, bob)
+
+	state := &accountState{Balance: big.NewInt(2), Nonce: 1, Code: "0x"}
+	assert.NoError(t, verifyAccountProof(root, bob, proofs[bob], state))
+
+	// The proof must be for the account
+	assert.Error(t, verifyAccountProof(root, alice, proofs[bob], state))
+
+	// The proof must match the state
+	assert.Error(t, verifyAccountProof(
+		root,
+		bob,
+		proofs[bob],
+		&accountState{Balance: big.NewInt(3), Nonce: 1, Code: "0x"},
+	))
+
+	// The proven account must match the proof
+	forged := *proofs[bob]
+	forged.Balance = (*hexutil.Big)(big.NewInt(3))
+	assert.Error(t, verifyAccountProof(
+		root,
+		bob,
+		&forged,
+		&accountState{Balance: big.NewInt(3), Nonce: 1, Code: "0x"},
+	))
+
+	// The proof must be rooted in the state root
+	assert.Error(t, verifyAccountProof(common.HexToHash("0x01"), bob, proofs[bob], state))
+}
+
+func TestBalance_IncludeProof(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	address := common.HexToAddress(testBalanceAccount)
+	root, proofs := testStateTrie(t, address)
+
+	header := &types.Header{
+		Root:       root,
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(100),
+	}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByHash",
+		header.Hash().Hex(),
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(**types.Header) = header
+		},
+	).Twice()
+
+	mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			*(r[0].Result.(*hexutil.Bytes)) = hexutil.Bytes{}
+			*(r[1].Result.(*hexutil.Big)) = hexutil.Big(*big.NewInt(1))
+			*(r[2].Result.(*hexutil.Uint64)) = 0
+		},
+	).Once()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getProof",
+		address,
+		[]string{},
+		toBlockHashArg(header.Hash()),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*AccountProof) = *proofs[address]
+		},
+	).Once()
+
+	resp, err := c.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{
+			Address:  testBalanceAccount,
+			Metadata: map[string]interface{}{"include_proof": true},
+		},
+		&RosettaTypes.PartialBlockIdentifier{Hash: RosettaTypes.String(header.Hash().Hex())},
+		nil,
+	)
+	assert.NoError(t, err)
+	proof := *proofs[address]
+	proof.Verified = true
+	assert.Equal(t, &proof, resp.Metadata["proof"])
+	assert.Equal(t, true, resp.Metadata["proof_verified"])
+	assert.NotContains(t, resp.Metadata, "proof_error")
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBalance_InvalidOptions(t *testing.T) {
+	c := &Client{}
+
+	resp, err := c.Balance(
+		context.Background(),
+		&RosettaTypes.AccountIdentifier{
+			Address:  testBalanceAccount,
+			Metadata: map[string]interface{}{"include_proof": "yes"},
+		},
+		nil,
+		nil,
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrInvalidBalanceOptions))
+}
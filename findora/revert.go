@@ -0,0 +1,155 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxRevertReasonLength is the maximum length of a decoded
+	// Error(string) revert reason. Longer reasons are ignored.
+	maxRevertReasonLength = 1024
+)
+
+var (
+	// errorSelector is the selector of Error(string),
+	// used by require() and revert().
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+	// panicSelector is the selector of Panic(uint256),
+	// used by failed assertions and arithmetic errors.
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	// panicReasons are the known Panic(uint256) codes.
+	//
+	// Source:
+	// https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+	panicReasons = map[uint64]string{
+		0x00: "generic panic",
+		0x01: "assert(false)",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "enum overflow",
+		0x22: "invalid encoded storage byte array accessed",
+		0x31: "out-of-bounds array access; popping on an empty array",
+		0x32: "out-of-bounds access of an array or bytesN",
+		0x41: "out of memory",
+		0x51: "uninitialized function",
+	}
+)
+
+// revertMetadata returns the operation metadata explaining why tx
+// failed when it is not traced. The transaction is replayed with
+// eth_call at the parent block, so the reason may be missing (or
+// differ) if tx depends on transactions earlier in its block. nil
+// is returned when the replay does not fail.
+func (ec *Client) revertMetadata(
+	ctx context.Context,
+	tx *loadedTransaction,
+) (map[string]interface{}, error) {
+	if tx.Receipt.BlockNumber == nil || tx.Receipt.BlockNumber.Sign() == 0 {
+		return nil, nil
+	}
+
+	callParams := map[string]interface{}{
+		"from":  tx.From,
+		"gas":   hexutil.EncodeUint64(tx.Transaction.Gas()),
+		"value": hexutil.EncodeBig(tx.Transaction.Value()),
+		"data":  hexutil.Encode(tx.Transaction.Data()),
+	}
+	if tx.Transaction.To() != nil {
+		callParams["to"] = tx.Transaction.To()
+	}
+
+	parent := new(big.Int).Sub(tx.Receipt.BlockNumber, big.NewInt(1))
+
+	var resp hexutil.Bytes
+	err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, toBlockNumArg(parent))
+	if err == nil {
+		return nil, nil
+	}
+
+	// Errors other than reverts (i.e. rate limits) are transient
+	if !isExecutionReverted(err) {
+		return nil, fmt.Errorf("%w: could not replay %s", err, tx.Transaction.Hash().Hex())
+	}
+
+	metadata := map[string]interface{}{
+		"error": err.Error(),
+	}
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return metadata, nil
+	}
+
+	rawData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return metadata, nil
+	}
+
+	data, decodeErr := hexutil.Decode(rawData)
+	if decodeErr != nil {
+		return metadata, nil
+	}
+
+	if reason, ok := decodeRevertReason(data); ok {
+		metadata["revert_reason"] = reason
+	}
+
+	return metadata, nil
+}
+
+// decodeRevertReason decodes Error(string) and Panic(uint256)
+// revert data, as returned by eth_call or in the output of
+// reverted calls by the callTracer. Custom errors are not decoded.
+func decodeRevertReason(data []byte) (string, bool) {
+	if len(data) < len(errorSelector) {
+		return "", false
+	}
+
+	selector, args := data[:len(errorSelector)], data[len(errorSelector):]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		// bytes32 values are not valid Error(string) arguments
+		if len(args) < 2*uint256Size {
+			return "", false
+		}
+
+		return decodeABIString(args, maxRevertReasonLength)
+	case bytes.Equal(selector, panicSelector):
+		if len(args) != uint256Size {
+			return "", false
+		}
+
+		code := new(big.Int).SetBytes(args)
+		reason, ok := panicReasons[code.Uint64()]
+		if !code.IsUint64() || !ok {
+			reason = "unknown panic"
+		}
+
+		return fmt.Sprintf("panic: %s (%#x)", reason, code), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testDataError is a reverted call error returned by the node
+// (implements rpc.Error and rpc.DataError).
+type testDataError struct {
+	data string
+}
+
+func (e *testDataError) Error() string          { return "execution reverted" }
+func (e *testDataError) ErrorCode() int         { return 3 } // nolint:gomnd
+func (e *testDataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevertReason(t *testing.T) {
+	tests := map[string]struct {
+		data   []byte
+		reason string
+		ok     bool
+	}{
+		"error": {
+			data:   append(append([]byte{}, errorSelector...), abiString("insufficient balance")...),
+			reason: "insufficient balance",
+			ok:     true,
+		},
+		"panic": {
+			data:   append(append([]byte{}, panicSelector...), common.LeftPadBytes([]byte{0x11}, uint256Size)...),
+			reason: "panic: arithmetic underflow or overflow (0x11)",
+			ok:     true,
+		},
+		"unknown panic": {
+			data:   append(append([]byte{}, panicSelector...), common.LeftPadBytes([]byte{0x99}, uint256Size)...),
+			reason: "panic: unknown panic (0x99)",
+			ok:     true,
+		},
+		"custom error": {
+			data: []byte{0x12, 0x34, 0x56, 0x78},
+		},
+		"truncated error": {
+			data: append(append([]byte{}, errorSelector...), 0x1),
+		},
+		"empty": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, ok := decodeRevertReason(test.data)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.reason, reason)
+		})
+	}
+}
+
+func TestCall_RevertReason(t *testing.T) {
+	revertData := append(append([]byte{}, errorSelector...), abiString("not allowed")...)
+	raw := fmt.Sprintf(`{
+		"type": "CALL",
+		"from": "0x687422eea2cb73b5d3e242ba5456b782919afc85",
+		"to": "0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a",
+		"value": "0x5",
+		"gasUsed": "0x5208",
+		"error": "execution reverted",
+		"output": "%s",
+		"calls": [{
+			"type": "CALL",
+			"from": "0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a",
+			"to": "0x687422eea2cb73b5d3e242ba5456b782919afc85",
+			"value": "0x1",
+			"gasUsed": "0x0"
+		}]
+	}`, hexutil.Encode(revertData))
+
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(raw), &call))
+	assert.Equal(t, "not allowed", call.RevertReason)
+
+	// Children of a reverted call share its reason
+	ops := traceOps(flattenTraces(&call, []*flatCall{}), 0)
+	assert.Len(t, ops, 4)
+	for _, op := range ops {
+		assert.Equal(t, RosettaTypes.String(FailureStatus), op.Status)
+		assert.Equal(t, map[string]interface{}{
+			"error":         "execution reverted",
+			"revert_reason": "not allowed",
+		}, op.Metadata)
+	}
+
+	// Reasons decoded by the tracer are used as is
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"type": "CALL",
+		"error": "execution reverted",
+		"revertReason": "paused"
+	}`), &call))
+	assert.Equal(t, "paused", call.RevertReason)
+
+	// The output of successful calls is ignored
+	call = Call{}
+	assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+		"type": "CALL",
+		"output": "%s"
+	}`, hexutil.Encode(revertData))), &call))
+	assert.Empty(t, call.RevertReason)
+}
+
+func TestPopulateTransaction_Failed(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{
+		p: AnvilChainConfig,
+		c: mockJSONRPC,
+	}
+
+	from := common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85")
+	to := common.HexToAddress("0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a")
+	loadedTx := &loadedTransaction{
+		Transaction: types.NewTransaction(0, to, big.NewInt(5), 100000, big.NewInt(1), []byte{0x1}),
+		From:        &from,
+		FeeAmount:   big.NewInt(21000),
+		Miner:       MustChecksum("0x0000000000000000000000000000000000000000"),
+		Receipt: &types.Receipt{
+			Status:      types.ReceiptStatusFailed,
+			BlockNumber: big.NewInt(10),
+		},
+	}
+
+	revertData := append(append([]byte{}, errorSelector...), abiString("not allowed")...)
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]interface{}{
+			"from":  &from,
+			"to":    &to,
+			"gas":   "0x186a0",
+			"value": "0x5",
+			"data":  "0x01",
+		},
+		"0x9",
+	).Return(
+		&testDataError{data: hexutil.Encode(revertData)},
+	).Once()
+
+	tx, err := c.populateTransaction(ctx, loadedTx)
+	assert.NoError(t, err)
+
+	metadata := map[string]interface{}{
+		"error":         "execution reverted",
+		"revert_reason": "not allowed",
+	}
+	assert.Len(t, tx.Operations, 4)
+	for _, op := range tx.Operations[2:] {
+		assert.Equal(t, CallOpType, op.Type)
+		assert.Equal(t, RosettaTypes.String(FailureStatus), op.Status)
+		assert.Equal(t, metadata, op.Metadata)
+	}
+
+	// Transient errors are returned
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		mock.Anything,
+		"0x9",
+	).Return(
+		&testNodeError{code: -32005, message: "limit exceeded"},
+	).Once()
+
+	_, err = c.populateTransaction(ctx, loadedTx)
+	assert.Error(t, err)
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		mock.Anything,
+		"0x9",
+	).Return(
+		errors.New("connection refused"),
+	).Once()
+
+	_, err = c.populateTransaction(ctx, loadedTx)
+	assert.Error(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+}
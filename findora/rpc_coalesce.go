@@ -0,0 +1,181 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/singleflight"
+)
+
+// uncoalescedMethods are the methods with side effects,
+// which are always sent to the node.
+var uncoalescedMethods = map[string]struct{}{
+	"eth_sendRawTransaction": {},
+}
+
+// coalescedCall identifies a coalesced call.
+type coalescedCall struct {
+	Method string        `json:"method"`
+	Args   []interface{} `json:"args"`
+}
+
+// batchResult is the result of a single
+// request of a coalesced batch.
+type batchResult struct {
+	result json.RawMessage
+	err    error
+}
+
+// coalescingRPC is a JSONRPC that coalesces identical concurrent
+// calls (same method and arguments) and batches (same requests in
+// the same order), so that the node serves each of them only once.
+// Every caller decodes its own copy of the response.
+type coalescingRPC struct {
+	c     JSONRPC
+	group singleflight.Group
+}
+
+// newCoalescingRPC returns a JSONRPC coalescing the calls sent to c.
+func newCoalescingRPC(c JSONRPC) *coalescingRPC {
+	return &coalescingRPC{c: c}
+}
+
+// CallContext implements JSONRPC.
+func (r *coalescingRPC) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	if _, ok := uncoalescedMethods[method]; ok {
+		return r.c.CallContext(ctx, result, method, args...)
+	}
+
+	key, err := json.Marshal(&coalescedCall{Method: method, Args: args})
+	if err != nil {
+		return r.c.CallContext(ctx, result, method, args...)
+	}
+
+	val, err := r.do(ctx, "call:"+string(key), func(ctx context.Context) (interface{}, error) {
+		var raw json.RawMessage
+		err := r.c.CallContext(ctx, &raw, method, args...)
+		return raw, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeRawResult(val.(json.RawMessage), result)
+}
+
+// BatchCallContext implements JSONRPC.
+func (r *coalescingRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	calls := make([]*coalescedCall, len(b))
+	for i, elem := range b {
+		if _, ok := uncoalescedMethods[elem.Method]; ok {
+			return r.c.BatchCallContext(ctx, b)
+		}
+
+		calls[i] = &coalescedCall{Method: elem.Method, Args: elem.Args}
+	}
+
+	key, err := json.Marshal(calls)
+	if err != nil {
+		return r.c.BatchCallContext(ctx, b)
+	}
+
+	val, err := r.do(ctx, "batch:"+string(key), func(ctx context.Context) (interface{}, error) {
+		batch := make([]rpc.BatchElem, len(b))
+		raws := make([]json.RawMessage, len(b))
+		for i, elem := range b {
+			batch[i] = rpc.BatchElem{
+				Method: elem.Method,
+				Args:   elem.Args,
+				Result: &raws[i],
+			}
+		}
+
+		if err := r.c.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+
+		results := make([]batchResult, len(b))
+		for i := range batch {
+			results[i] = batchResult{result: raws[i], err: batch[i].Error}
+		}
+
+		return results, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, res := range val.([]batchResult) {
+		b[i].Error = res.err
+		if res.err == nil {
+			b[i].Error = decodeRawResult(res.result, b[i].Result)
+		}
+	}
+
+	return nil
+}
+
+// Close implements JSONRPC.
+func (r *coalescingRPC) Close() {
+	r.c.Close()
+}
+
+// do runs fn once for all concurrent callers with the same key. fn is
+// run with the context of the first caller: if it is canceled while
+// other callers are waiting, they run fn again with their own context.
+func (r *coalescingRPC) do(
+	ctx context.Context,
+	key string,
+	fn func(ctx context.Context) (interface{}, error),
+) (interface{}, error) {
+	ch := r.group.DoChan(key, func() (interface{}, error) {
+		return fn(ctx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil && res.Shared && ctx.Err() == nil &&
+			(errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+			return fn(ctx)
+		}
+
+		return res.Val, res.Err
+	}
+}
+
+// decodeRawResult decodes raw into result like rpc.Client
+// does. A missing result is decoded as null.
+func decodeRawResult(raw json.RawMessage, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+
+	if len(raw) == 0 {
+		raw = json.RawMessage("null")
+	}
+
+	return json.Unmarshal(raw, result)
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCoalesceService is served as the "test" namespace. Its
+// calls block until release is closed and are counted.
+type testCoalesceService struct {
+	calls   int32
+	release chan struct{}
+}
+
+type testCoalesceResult struct {
+	Number hexutil.Uint64 `json:"number"`
+}
+
+func (s *testCoalesceService) Block(number hexutil.Uint64) *testCoalesceResult {
+	atomic.AddInt32(&s.calls, 1)
+	<-s.release
+	return &testCoalesceResult{Number: number}
+}
+
+func (s *testCoalesceService) Missing() *testCoalesceResult {
+	atomic.AddInt32(&s.calls, 1)
+	return nil
+}
+
+func newTestCoalescingRPC(t *testing.T) (*coalescingRPC, *testCoalesceService) {
+	service := &testCoalesceService{release: make(chan struct{})}
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("test", service))
+	t.Cleanup(server.Stop)
+
+	return newCoalescingRPC(rpc.DialInProc(server)), service
+}
+
+func TestCoalescingRPC_CallContext(t *testing.T) {
+	r, service := newTestCoalescingRPC(t)
+	ctx := context.Background()
+
+	results := make([]*testCoalesceResult, 10)
+	var wg sync.WaitGroup
+	for i := range results {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, r.CallContext(ctx, &results[i], "test_block", hexutil.Uint64(7)))
+		}()
+	}
+
+	// Let every call join the first one
+	time.Sleep(100 * time.Millisecond)
+	close(service.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&service.calls))
+	for _, result := range results {
+		assert.Equal(t, &testCoalesceResult{Number: 7}, result)
+	}
+
+	// Every caller gets its own copy of the result
+	assert.NotSame(t, results[0], results[1])
+
+	// Calls with different arguments are not coalesced
+	var other *testCoalesceResult
+	assert.NoError(t, r.CallContext(ctx, &other, "test_block", hexutil.Uint64(8)))
+	assert.Equal(t, &testCoalesceResult{Number: 8}, other)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&service.calls))
+
+	// Null results are decoded like rpc.Client does
+	missing := &testCoalesceResult{}
+	assert.NoError(t, r.CallContext(ctx, &missing, "test_missing"))
+	assert.Nil(t, missing)
+}
+
+func TestCoalescingRPC_CanceledLeader(t *testing.T) {
+	r, service := newTestCoalescingRPC(t)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		var result *testCoalesceResult
+		leaderErr <- r.CallContext(leaderCtx, &result, "test_block", hexutil.Uint64(7))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	followerErr := make(chan error, 1)
+	var result *testCoalesceResult
+	go func() {
+		followerErr <- r.CallContext(context.Background(), &result, "test_block", hexutil.Uint64(7))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// The follower retries with its own context
+	cancel()
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(service.release)
+	assert.NoError(t, <-followerErr)
+	assert.Equal(t, &testCoalesceResult{Number: 7}, result)
+}
+
+func TestCoalescingRPC_BatchCallContext(t *testing.T) {
+	r, service := newTestCoalescingRPC(t)
+	close(service.release)
+
+	var block *testCoalesceResult
+	missing := &testCoalesceResult{}
+	batch := []rpc.BatchElem{
+		{Method: "test_block", Args: []interface{}{hexutil.Uint64(3)}, Result: &block},
+		{Method: "test_missing", Result: &missing},
+		{Method: "test_unknown", Result: new(string)},
+	}
+	assert.NoError(t, r.BatchCallContext(context.Background(), batch))
+
+	assert.NoError(t, batch[0].Error)
+	assert.Equal(t, &testCoalesceResult{Number: 3}, block)
+	assert.NoError(t, batch[1].Error)
+	assert.Nil(t, missing)
+	assert.Error(t, batch[2].Error)
+}
@@ -0,0 +1,269 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// tipStaleIntervals is the number of poll intervals after which
+	// the tip is considered stale if it could not be refreshed.
+	tipStaleIntervals = 3
+
+	// tipHeadBuffer is the size of the buffer of
+	// headers received from the newHeads subscription.
+	tipHeadBuffer = 16
+)
+
+// headSubscriber subscribes to the new heads of the chain.
+type headSubscriber func(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+
+// wsHeadSubscriber returns a headSubscriber using
+// a newHeads subscription on the WebSocket endpoint url.
+func wsHeadSubscriber(url string) headSubscriber {
+	return func(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+		c, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		sub, err := c.EthSubscribe(ctx, ch, "newHeads")
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+
+		return &closingSubscription{Subscription: sub, c: c}, nil
+	}
+}
+
+// closingSubscription closes its client when unsubscribed.
+type closingSubscription struct {
+	ethereum.Subscription
+	c *rpc.Client
+}
+
+// Unsubscribe implements ethereum.Subscription.
+func (s *closingSubscription) Unsubscribe() {
+	s.Subscription.Unsubscribe()
+	s.c.Close()
+}
+
+// tipTracker keeps the head of the chain and the sync status of the
+// node up to date in the background, so that they can be returned
+// without querying the node. New heads are received from a newHeads
+// subscription when available. Otherwise, the head is polled every
+// interval. The sync status is always polled.
+type tipTracker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	ec        *Client
+	interval  time.Duration
+	subscribe headSubscriber // nil if only polling
+
+	mu       sync.RWMutex
+	header   *types.Header
+	progress *ethereum.SyncProgress
+	checked  time.Time
+
+	// warming is set (atomically) while the block cache is warmed.
+	warming int32
+}
+
+// newTipTracker starts a tipTracker polling every interval, or returns
+// nil if interval is not positive. When subscribe is not nil, it is
+// used to receive new heads instead of polling them.
+func newTipTracker(ec *Client, interval time.Duration, subscribe headSubscriber) *tipTracker {
+	if interval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &tipTracker{
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		ec:        ec,
+		interval:  interval,
+		subscribe: subscribe,
+	}
+	go t.run()
+
+	return t
+}
+
+// Tip returns the head of the chain and the sync status of the node
+// (nil when synced). ok is false if the tip has not been fetched yet
+// or if it could not be refreshed recently.
+func (t *tipTracker) Tip() (header *types.Header, progress *ethereum.SyncProgress, ok bool) {
+	if t == nil {
+		return nil, nil, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.header == nil || time.Since(t.checked) > tipStaleIntervals*t.interval {
+		return nil, nil, false
+	}
+
+	return t.header, t.progress, true
+}
+
+// Close stops tracking the tip.
+func (t *tipTracker) Close() {
+	if t == nil {
+		return
+	}
+
+	t.cancel()
+	<-t.done
+}
+
+func (t *tipTracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	heads := make(chan *types.Header, tipHeadBuffer)
+	var sub ethereum.Subscription
+	var subErr <-chan error
+	defer func() {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+	}()
+
+	subscribe := func() {
+		if t.subscribe == nil {
+			return
+		}
+
+		var err error
+		sub, err = t.subscribe(t.ctx, heads)
+		if err != nil {
+			log.Printf("%s: unable to subscribe to new heads, polling\n", err.Error())
+			sub = nil
+			return
+		}
+
+		subErr = sub.Err()
+	}
+
+	subscribe()
+	t.refresh(true)
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case header := <-heads:
+			t.observeHead(header)
+		case err := <-subErr:
+			log.Printf("%v: new heads subscription failed, polling\n", err)
+			sub.Unsubscribe()
+			sub, subErr = nil, nil
+		case <-ticker.C:
+			if sub == nil {
+				subscribe()
+			}
+
+			// Heads are only polled when not subscribed
+			t.refresh(sub == nil)
+		}
+	}
+}
+
+// refresh polls the sync status of the node and,
+// if pollHead is set, the head of the chain.
+func (t *tipTracker) refresh(pollHead bool) {
+	t.mu.RLock()
+	header := t.header
+	t.mu.RUnlock()
+
+	if pollHead || header == nil {
+		var err error
+		header, err = t.ec.blockHeaderByNumber(t.ctx, nil)
+		if err != nil {
+			return
+		}
+	}
+
+	progress, err := t.ec.syncProgress(t.ctx)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.header = header
+	t.progress = progress
+	t.checked = time.Now()
+	t.mu.Unlock()
+
+	t.observed(header)
+}
+
+// observeHead records a head received from the subscription.
+func (t *tipTracker) observeHead(header *types.Header) {
+	t.mu.Lock()
+	if t.header == nil {
+		// The sync status is not known yet
+		t.mu.Unlock()
+		return
+	}
+	t.header = header
+	t.checked = time.Now()
+	t.mu.Unlock()
+
+	t.observed(header)
+}
+
+// observed records header as the tip of the block cache and warms
+// the cache with the block that has just become confirmed enough to
+// be cached.
+func (t *tipTracker) observed(header *types.Header) {
+	cache := t.ec.blockCache
+	if cache == nil {
+		return
+	}
+
+	cache.ObserveTip(header.Number.Int64())
+
+	index := header.Number.Int64() - cache.confirmations
+	if index < 0 || cache.Contains(index) {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&t.warming, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&t.warming, 0)
+
+		_, _ = t.ec.blockByIndex(t.ctx, index)
+	}()
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testSubscription is an ethereum.Subscription that never fails.
+type testSubscription struct {
+	err chan error
+}
+
+func (s *testSubscription) Unsubscribe() {}
+
+func (s *testSubscription) Err() <-chan error {
+	return s.err
+}
+
+// mockTip makes mockJSONRPC return number as the head of the chain.
+func mockTip(mockJSONRPC *mocks.JSONRPC, number int64) {
+	mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"latest",
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			header := args.Get(1).(**types.Header)
+			*header = &types.Header{Number: big.NewInt(number), Difficulty: new(big.Int)}
+		},
+	)
+
+	mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_syncing",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			status := args.Get(1).(*json.RawMessage)
+			*status = json.RawMessage("false")
+		},
+	)
+}
+
+func TestTipTracker_Polling(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTip(mockJSONRPC, 10)
+
+	c := &Client{c: mockJSONRPC, blockCache: newBlockCache(10, 20)}
+	c.tip = newTipTracker(c, 10*time.Millisecond, nil)
+	defer c.tip.Close()
+
+	assert.Eventually(t, func() bool {
+		_, _, ok := c.tip.Tip()
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	header, progress, ok := c.tip.Tip()
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), header.Number.Int64())
+	assert.Nil(t, progress)
+
+	// The block cache sees the tip
+	assert.True(t, c.blockCache.Confirmed(-10))
+	assert.False(t, c.blockCache.Confirmed(-9))
+
+	// Status is answered from memory
+	block, _, syncStatus, _, err := c.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), block.Index)
+	assert.Nil(t, syncStatus)
+}
+
+func TestTipTracker_Subscription(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockTip(mockJSONRPC, 10)
+
+	heads := make(chan chan<- *types.Header, 1)
+	subscribe := func(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+		heads <- ch
+		return &testSubscription{err: make(chan error)}, nil
+	}
+
+	c := &Client{c: mockJSONRPC}
+	c.tip = newTipTracker(c, 10*time.Millisecond, subscribe)
+	defer c.tip.Close()
+
+	ch := <-heads
+	assert.Eventually(t, func() bool {
+		_, _, ok := c.tip.Tip()
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	// New heads are taken from the subscription, not polled
+	ch <- &types.Header{Number: big.NewInt(11), Difficulty: new(big.Int)}
+	assert.Eventually(t, func() bool {
+		header, _, ok := c.tip.Tip()
+		return ok && header.Number.Int64() == 11
+	}, time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	header, _, ok := c.tip.Tip()
+	assert.True(t, ok)
+	assert.Equal(t, int64(11), header.Number.Int64())
+}
+
+func TestTipTracker_Disabled(t *testing.T) {
+	tracker := newTipTracker(&Client{}, 0, nil)
+	assert.Nil(t, tracker)
+
+	_, _, ok := tracker.Tip()
+	assert.False(t, ok)
+	tracker.Close()
+}
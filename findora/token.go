@@ -0,0 +1,285 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+)
+
+const (
+	// erc20TransferTopicCount is the number of topics of an ERC-20
+	// Transfer log (ERC-721 also indexes the token id, so it has 4).
+	erc20TransferTopicCount = 3
+
+	// uint256Size is the size of an ABI encoded uint256.
+	uint256Size = 32
+)
+
+var (
+	// transferEventTopic is the topic of the
+	// Transfer(address,address,uint256) event.
+	transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// balanceOfSelector is the selector of balanceOf(address).
+	balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+)
+
+// tokenCurrency returns the *RosettaTypes.Currency of the token
+// deployed at contract. The contract address is always part of the
+// currency metadata so that tokens sharing a symbol are not mixed up.
+//
+// Tokens in the token registry take precedence over discovered tokens.
+// Contracts that are not ERC-20 compliant (or when discovery is disabled)
+// use UnknownTokenSymbol and 0 decimals, so amounts are in raw units.
+// Discovery errors are returned so that the currency of a token never
+// depends on the health of the node.
+func (ec *Client) tokenCurrency(
+	ctx context.Context,
+	contract common.Address,
+) (*RosettaTypes.Currency, error) {
+	symbol, decimals := UnknownTokenSymbol, int32(0)
+	if token, ok := ec.tokens.Token(contract); ok {
+		symbol, decimals = token.Symbol, token.Decimals
+	} else if ec.discovery != nil {
+		token, err := ec.discovery.Token(ctx, contract)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Compliant {
+			symbol, decimals = token.Symbol, token.Decimals
+		}
+	}
+
+	return &RosettaTypes.Currency{
+		Symbol:   symbol,
+		Decimals: decimals,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: MustChecksum(contract.Hex()),
+		},
+	}, nil
+}
+
+// tokenContract returns the contract address of a fungible token
+// *RosettaTypes.Currency. The contract address in the currency
+// metadata takes precedence over a token registry lookup by symbol.
+func (ec *Client) tokenContract(currency *RosettaTypes.Currency) (common.Address, error) {
+	// Balances of non-fungible tokens are not supported
+	if _, ok := currency.Metadata[TokenIDKey]; ok {
+		return common.Address{}, fmt.Errorf("%w: %s is a non-fungible token", ErrCurrencyNotSupported, currency.Symbol)
+	}
+
+	if rawContract, ok := currency.Metadata[ContractAddressKey]; ok {
+		contract, ok := rawContract.(string)
+		if !ok {
+			return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidContractAddress, rawContract)
+		}
+
+		checksum, ok := ChecksumAddress(contract)
+		if !ok {
+			return common.Address{}, fmt.Errorf("%w: %s", ErrInvalidContractAddress, contract)
+		}
+
+		return common.HexToAddress(checksum), nil
+	}
+
+	contract, ok := ec.tokens.Contract(currency.Symbol)
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrCurrencyNotSupported, currency.Symbol)
+	}
+
+	return contract, nil
+}
+
+// tokenBalance returns the balance of account in the token
+// deployed at contract by calling balanceOf at blockQuery.
+func (ec *Client) tokenBalance(
+	ctx context.Context,
+	contract common.Address,
+	account common.Address,
+	blockQuery interface{},
+) (*big.Int, error) {
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(account.Bytes(), uint256Size)...)
+	callParams := map[string]string{
+		"to":   contract.Hex(),
+		"data": hexutil.Encode(data),
+	}
+
+	var resp hexutil.Bytes
+	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, blockQuery); err != nil {
+		return nil, err
+	}
+
+	if len(resp) != uint256Size {
+		return nil, fmt.Errorf("%w: balanceOf returned %d bytes", ErrTokenCallFailed, len(resp))
+	}
+
+	return new(big.Int).SetBytes(resp), nil
+}
+
+// tokenTransfer is a token transfer decoded from a receipt log.
+type tokenTransfer struct {
+	OpType   string
+	Contract common.Address
+	From     common.Address
+	To       common.Address
+	Value    *big.Int
+	LogIndex uint
+
+	// TokenID is nil for ERC-20 transfers.
+	TokenID *big.Int
+
+	// Metadata is added to the metadata of the transfer operations.
+	Metadata map[string]interface{}
+}
+
+// decodeERC20Transfer decodes log if it is an ERC-20 Transfer log.
+func decodeERC20Transfer(log *types.Log) (*tokenTransfer, bool) {
+	if len(log.Topics) != erc20TransferTopicCount || log.Topics[0] != transferEventTopic {
+		return nil, false
+	}
+
+	if len(log.Data) != uint256Size {
+		return nil, false
+	}
+
+	return &tokenTransfer{
+		OpType:   ERC20TransferOpType,
+		Contract: log.Address,
+		From:     common.BytesToAddress(log.Topics[1].Bytes()),
+		To:       common.BytesToAddress(log.Topics[2].Bytes()),
+		Value:    new(big.Int).SetBytes(log.Data),
+		LogIndex: log.Index,
+	}, true
+}
+
+// decodeTokenTransfers decodes all ERC-20, ERC-721 and ERC-1155
+// transfers in log (an ERC-1155 TransferBatch log contains several).
+func decodeTokenTransfers(log *types.Log) []*tokenTransfer {
+	if transfer, ok := decodeERC20Transfer(log); ok {
+		return []*tokenTransfer{transfer}
+	}
+
+	if transfer, ok := decodeERC721Transfer(log); ok {
+		return []*tokenTransfer{transfer}
+	}
+
+	return decodeERC1155Transfers(log)
+}
+
+// transferCurrency returns the *RosettaTypes.Currency
+// of the token moved by transfer.
+func (ec *Client) transferCurrency(
+	ctx context.Context,
+	transfer *tokenTransfer,
+) (*RosettaTypes.Currency, error) {
+	if transfer.TokenID != nil {
+		return ec.nftCurrency(transfer.Contract, transfer.TokenID), nil
+	}
+
+	return ec.tokenCurrency(ctx, transfer.Contract)
+}
+
+// tokenTransferOps returns a debit and a credit *RosettaTypes.Operation
+// for every ERC-20 (FRC-20), ERC-721 and ERC-1155 transfer in the receipt
+// of tx. Mints and burns only have a credit (resp. debit) operation
+// because the zero address is not a real account.
+func (ec *Client) tokenTransferOps(
+	ctx context.Context,
+	tx *loadedTransaction,
+	startIndex int,
+) ([]*RosettaTypes.Operation, error) {
+	var ops []*RosettaTypes.Operation
+	if tx.Receipt == nil || tx.Receipt.Status != types.ReceiptStatusSuccessful {
+		return ops, nil
+	}
+
+	for _, log := range tx.Receipt.Logs {
+		for _, transfer := range decodeTokenTransfers(log) {
+			if transfer.Value.Sign() == 0 {
+				continue
+			}
+
+			currency, err := ec.transferCurrency(ctx, transfer)
+			if err != nil {
+				return nil, err
+			}
+
+			metadata := map[string]interface{}{
+				"log_index": transfer.LogIndex,
+			}
+			for k, v := range transfer.Metadata {
+				metadata[k] = v
+			}
+
+			var debitIndex *int64
+			if transfer.From != (common.Address{}) {
+				index := int64(len(ops) + startIndex)
+				debitIndex = &index
+				ops = append(ops, &RosettaTypes.Operation{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: index,
+					},
+					Type:   transfer.OpType,
+					Status: RosettaTypes.String(SuccessStatus),
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: MustChecksum(transfer.From.Hex()),
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    new(big.Int).Neg(transfer.Value).String(),
+						Currency: currency,
+					},
+					Metadata: metadata,
+				})
+			}
+
+			if transfer.To != (common.Address{}) {
+				creditOp := &RosettaTypes.Operation{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: int64(len(ops) + startIndex),
+					},
+					Type:   transfer.OpType,
+					Status: RosettaTypes.String(SuccessStatus),
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: MustChecksum(transfer.To.Hex()),
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    transfer.Value.String(),
+						Currency: currency,
+					},
+					Metadata: metadata,
+				}
+				if debitIndex != nil {
+					creditOp.RelatedOperations = []*RosettaTypes.OperationIdentifier{
+						{
+							Index: *debitIndex,
+						},
+					}
+				}
+				ops = append(ops, creditOp)
+			}
+		}
+	}
+
+	return ops, nil
+}
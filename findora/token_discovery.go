@@ -0,0 +1,306 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// maxTokenSymbolLength is the maximum length of a discovered
+	// token symbol. Longer symbols are considered invalid.
+	maxTokenSymbolLength = 32
+
+	// maxTokenNameLength is the maximum length of a discovered
+	// token name. Longer names are ignored.
+	maxTokenNameLength = 128
+
+	// maxTokenDecimals is the maximum value of a discovered
+	// token decimals (decimals is an uint8 in ERC-20).
+	maxTokenDecimals = 255
+
+	// tokenDiscoveryTimeout bounds the discovery of a token. It is
+	// not tied to the request that triggered it, as concurrent
+	// requests share its result.
+	tokenDiscoveryTimeout = 10 * time.Second
+)
+
+var (
+	nameSelector     = crypto.Keccak256([]byte("name()"))[:4]
+	symbolSelector   = crypto.Keccak256([]byte("symbol()"))[:4]
+	decimalsSelector = crypto.Keccak256([]byte("decimals()"))[:4]
+)
+
+// discoveredToken is the metadata discovered for a token contract.
+// Contracts that are not ERC-20 compliant are cached with
+// Compliant set to false so that they are not queried again.
+type discoveredToken struct {
+	Name      string `json:"name,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Decimals  int32  `json:"decimals"`
+	Compliant bool   `json:"compliant"`
+}
+
+// tokenDiscovery discovers the metadata of unknown token contracts
+// with name(), symbol() and decimals() eth_call requests. Results are
+// cached in memory and, when a cache file is configured, on disk so
+// that the currency of a token never changes across restarts.
+type tokenDiscovery struct {
+	c         JSONRPC
+	cacheFile string
+
+	group singleflight.Group
+
+	mu     sync.RWMutex
+	tokens map[common.Address]*discoveredToken
+}
+
+// newTokenDiscovery creates a tokenDiscovery and loads its cache
+// file, if any.
+func newTokenDiscovery(c JSONRPC, cacheFile string) (*tokenDiscovery, error) {
+	d := &tokenDiscovery{
+		c:         c,
+		cacheFile: cacheFile,
+		tokens:    map[common.Address]*discoveredToken{},
+	}
+
+	if len(cacheFile) == 0 {
+		return d, nil
+	}
+
+	data, err := ioutil.ReadFile(cacheFile) // #nosec G304
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read token cache", err)
+	}
+
+	if err := json.Unmarshal(data, &d.tokens); err != nil {
+		return nil, fmt.Errorf("%w: could not parse token cache", err)
+	}
+
+	return d, nil
+}
+
+// Token returns the metadata of the token deployed at contract,
+// discovering it the first time contract is seen. Concurrent calls
+// for the same contract share a single discovery, which is not
+// cancelled when ctx is: ctx only bounds how long the caller waits.
+func (d *tokenDiscovery) Token(
+	ctx context.Context,
+	contract common.Address,
+) (*discoveredToken, error) {
+	d.mu.RLock()
+	token, ok := d.tokens[contract]
+	d.mu.RUnlock()
+	if ok {
+		return token, nil
+	}
+
+	ch := d.group.DoChan(contract.Hex(), func() (interface{}, error) {
+		discoverCtx, cancel := context.WithTimeout(context.Background(), tokenDiscoveryTimeout)
+		defer cancel()
+
+		token, err := d.discover(discoverCtx, contract)
+		if err != nil {
+			return nil, err
+		}
+
+		d.mu.Lock()
+		d.tokens[contract] = token
+		d.mu.Unlock()
+
+		if err := d.save(); err != nil {
+			log.Printf("%s: unable to save token cache\n", err.Error())
+		}
+
+		return token, nil
+	})
+
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*discoveredToken), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// discover calls name(), symbol() and decimals() on contract.
+// A contract is considered ERC-20 compliant when symbol() and
+// decimals() both return valid values (name() is optional).
+//
+// The calls are made at the latest block: the result is cached for
+// every block, so it must not depend on the block being fetched.
+func (d *tokenDiscovery) discover(
+	ctx context.Context,
+	contract common.Address,
+) (*discoveredToken, error) {
+	selectors := [][]byte{nameSelector, symbolSelector, decimalsSelector}
+	results := make([]hexutil.Bytes, len(selectors))
+	reqs := make([]rpc.BatchElem, len(selectors))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]string{
+					"to":   contract.Hex(),
+					"data": hexutil.Encode(selectors[i]),
+				},
+				toBlockNumArg(nil),
+			},
+			Result: &results[i],
+		}
+	}
+
+	if err := d.c.BatchCallContext(ctx, reqs); err != nil {
+		return nil, fmt.Errorf("%w: unable to discover token %s", err, contract.Hex())
+	}
+
+	callErrs := make([]bool, len(reqs))
+	for i := range reqs {
+		if reqs[i].Error == nil {
+			continue
+		}
+
+		// Reverted calls mean that the method is not implemented. Any
+		// other error (i.e. rate limits or missing state) is transient
+		// and must not be cached.
+		if !isExecutionReverted(reqs[i].Error) {
+			return nil, fmt.Errorf("%w: unable to discover token %s", reqs[i].Error, contract.Hex())
+		}
+		callErrs[i] = true
+	}
+
+	token := &discoveredToken{}
+	if !callErrs[0] {
+		token.Name, _ = decodeABIString(results[0], maxTokenNameLength)
+	}
+
+	symbol, symbolOk := "", false
+	if !callErrs[1] {
+		symbol, symbolOk = decodeABIString(results[1], maxTokenSymbolLength)
+	}
+
+	decimals, decimalsOk := int32(0), false
+	if !callErrs[2] {
+		decimals, decimalsOk = decodeABIDecimals(results[2])
+	}
+
+	if symbolOk && decimalsOk {
+		token.Symbol = symbol
+		token.Decimals = decimals
+		token.Compliant = true
+	}
+
+	return token, nil
+}
+
+// save writes the cache to the cache file, if any.
+func (d *tokenDiscovery) save() error {
+	if len(d.cacheFile) == 0 {
+		return nil
+	}
+
+	d.mu.RLock()
+	data, err := json.MarshalIndent(d.tokens, "", " ")
+	d.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.cacheFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that the cache is never
+	// left half written.
+	tmpFile := d.cacheFile + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, os.FileMode(0600)); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, d.cacheFile)
+}
+
+// decodeABIString decodes the return value of name() or symbol().
+// Both ABI encoded strings and bytes32 values (used by some early
+// tokens) are supported. Empty, invalid UTF-8 and values longer
+// than maxLength are rejected.
+func decodeABIString(data []byte, maxLength int) (string, bool) {
+	var raw []byte
+	switch {
+	case len(data) == uint256Size:
+		raw = bytes.TrimRight(data, "\x00")
+	case len(data) >= 2*uint256Size:
+		offset := new(big.Int).SetBytes(data[:uint256Size])
+		if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-uint256Size) {
+			return "", false
+		}
+
+		start := offset.Uint64() + uint256Size
+		length := new(big.Int).SetBytes(data[offset.Uint64():start])
+		if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+			return "", false
+		}
+
+		raw = data[start : start+length.Uint64()]
+	default:
+		return "", false
+	}
+
+	value := string(raw)
+	if len(value) == 0 || len(value) > maxLength || !utf8.ValidString(value) {
+		return "", false
+	}
+
+	return value, true
+}
+
+// decodeABIDecimals decodes the return value of decimals().
+func decodeABIDecimals(data []byte) (int32, bool) {
+	if len(data) != uint256Size {
+		return 0, false
+	}
+
+	decimals := new(big.Int).SetBytes(data)
+	if decimals.Cmp(big.NewInt(maxTokenDecimals)) > 0 {
+		return 0, false
+	}
+
+	return int32(decimals.Int64()), true
+}
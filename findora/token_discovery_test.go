@@ -0,0 +1,275 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testRPCError is an error returned by the node (implements rpc.Error).
+type testRPCError struct{}
+
+func (e *testRPCError) Error() string  { return "execution reverted" }
+func (e *testRPCError) ErrorCode() int { return 3 } // nolint:gomnd
+
+// testNodeError is any other error returned by the node.
+type testNodeError struct {
+	code    int
+	message string
+}
+
+func (e *testNodeError) Error() string  { return e.message }
+func (e *testNodeError) ErrorCode() int { return e.code }
+
+func abiString(value string) []byte {
+	data := common.LeftPadBytes([]byte{0x20}, uint256Size)
+	data = append(data, common.LeftPadBytes([]byte{byte(len(value))}, uint256Size)...)
+	return append(data, common.RightPadBytes([]byte(value), uint256Size)...)
+}
+
+func mockDiscovery(
+	mockJSONRPC *mocks.JSONRPC,
+	results []hexutil.Bytes,
+	errs []error,
+) {
+	// Discovery runs with its own context
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			for i := range r {
+				*(r[i].Result.(*hexutil.Bytes)) = results[i]
+				r[i].Error = errs[i]
+			}
+		},
+	).Once()
+}
+
+func TestTokenDiscovery(t *testing.T) {
+	ctx := context.Background()
+	cacheFile := filepath.Join(t.TempDir(), "tokens", "cache.json")
+
+	mockJSONRPC := &mocks.JSONRPC{}
+	d, err := newTokenDiscovery(mockJSONRPC, cacheFile)
+	assert.NoError(t, err)
+
+	// Compliant token
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{
+		abiString("Tether USD"),
+		abiString("USDT"),
+		common.LeftPadBytes([]byte{6}, uint256Size),
+	}, []error{nil, nil, nil})
+	token, err := d.Token(ctx, testTokenContract)
+	assert.NoError(t, err)
+	assert.Equal(t, &discoveredToken{
+		Name:      "Tether USD",
+		Symbol:    "USDT",
+		Decimals:  6,
+		Compliant: true,
+	}, token)
+
+	// Token with a bytes32 symbol and no name()
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{
+		nil,
+		common.RightPadBytes([]byte("MKR"), uint256Size),
+		common.LeftPadBytes([]byte{18}, uint256Size),
+	}, []error{&testRPCError{}, nil, nil})
+	token, err = d.Token(ctx, testTokenFrom)
+	assert.NoError(t, err)
+	assert.Equal(t, &discoveredToken{Symbol: "MKR", Decimals: 18, Compliant: true}, token)
+
+	// Contract that is not ERC-20 compliant
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{nil, nil, nil}, []error{
+		&testRPCError{},
+		&testRPCError{},
+		&testRPCError{},
+	})
+	token, err = d.Token(ctx, testTokenTo)
+	assert.NoError(t, err)
+	assert.False(t, token.Compliant)
+
+	// Reverts without revert data are reported with another code
+	reverting := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	reverted := &testNodeError{code: -32000, message: "execution reverted"}
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{nil, nil, nil}, []error{
+		reverted,
+		reverted,
+		reverted,
+	})
+	token, err = d.Token(ctx, reverting)
+	assert.NoError(t, err)
+	assert.False(t, token.Compliant)
+
+	// Other node errors are transient and not cached
+	limited := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{nil, nil, nil}, []error{
+		nil,
+		&testNodeError{code: -32005, message: "limit exceeded"},
+		nil,
+	})
+	_, err = d.Token(ctx, limited)
+	assert.Error(t, err)
+
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{
+		nil,
+		abiString("FRC"),
+		common.LeftPadBytes([]byte{18}, uint256Size),
+	}, []error{nil, nil, nil})
+	token, err = d.Token(ctx, limited)
+	assert.NoError(t, err)
+	assert.Equal(t, &discoveredToken{Symbol: "FRC", Decimals: 18, Compliant: true}, token)
+
+	// Cached tokens are not discovered again, even after a restart
+	token, err = d.Token(ctx, testTokenContract)
+	assert.NoError(t, err)
+	assert.Equal(t, "USDT", token.Symbol)
+
+	reloaded, err := newTokenDiscovery(mockJSONRPC, cacheFile)
+	assert.NoError(t, err)
+	token, err = reloaded.Token(ctx, testTokenTo)
+	assert.NoError(t, err)
+	assert.False(t, token.Compliant)
+
+	// Transient errors are returned and not cached
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		errors.New("connection refused"),
+	).Once()
+	_, err = d.Token(ctx, other)
+	assert.Error(t, err)
+
+	// Canceling the caller does not cancel the shared discovery
+	release := make(chan struct{})
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			<-release
+			r := args.Get(1).([]rpc.BatchElem)
+			*(r[1].Result.(*hexutil.Bytes)) = abiString("FBTC")
+			*(r[2].Result.(*hexutil.Bytes)) = common.LeftPadBytes([]byte{8}, uint256Size)
+		},
+	).Once()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = d.Token(canceled, other)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	close(release)
+	token, err = d.Token(ctx, other)
+	assert.NoError(t, err)
+	assert.Equal(t, &discoveredToken{Symbol: "FBTC", Decimals: 8, Compliant: true}, token)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestTokenCurrency_Discovery(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	d, err := newTokenDiscovery(mockJSONRPC, "")
+	assert.NoError(t, err)
+	c := &Client{c: mockJSONRPC, discovery: d}
+
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{
+		abiString("Findora USD"),
+		abiString("FUSD"),
+		common.LeftPadBytes([]byte{18}, uint256Size),
+	}, []error{nil, nil, nil})
+	currency, err := c.tokenCurrency(ctx, testTokenContract)
+	assert.NoError(t, err)
+	assert.Equal(t, "FUSD", currency.Symbol)
+	assert.Equal(t, int32(18), currency.Decimals)
+	assert.Equal(t, MustChecksum(testTokenContract.Hex()), currency.Metadata[ContractAddressKey])
+
+	// Non-compliant contracts fall back to the unknown token currency
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{nil, abiString("X"), nil}, []error{
+		&testRPCError{},
+		nil,
+		&testRPCError{},
+	})
+	currency, err = c.tokenCurrency(ctx, testTokenTo)
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownTokenSymbol, currency.Symbol)
+	assert.Equal(t, int32(0), currency.Decimals)
+
+	// Transient discovery errors are returned and not cached
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	mockJSONRPC.On(
+		"BatchCallContext",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		errors.New("connection refused"),
+	).Once()
+	currency, err = c.tokenCurrency(ctx, other)
+	assert.Error(t, err)
+	assert.Nil(t, currency)
+
+	mockDiscovery(mockJSONRPC, []hexutil.Bytes{
+		abiString("Findora BTC"),
+		abiString("FBTC"),
+		common.LeftPadBytes([]byte{8}, uint256Size),
+	}, []error{nil, nil, nil})
+	currency, err = c.tokenCurrency(ctx, other)
+	assert.NoError(t, err)
+	assert.Equal(t, "FBTC", currency.Symbol)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestDecodeABIString(t *testing.T) {
+	value, ok := decodeABIString(abiString("USDT"), maxTokenSymbolLength)
+	assert.True(t, ok)
+	assert.Equal(t, "USDT", value)
+
+	_, ok = decodeABIString(abiString(""), maxTokenSymbolLength)
+	assert.False(t, ok)
+
+	_, ok = decodeABIString([]byte{0x1}, maxTokenSymbolLength)
+	assert.False(t, ok)
+
+	// Out of bounds offset
+	invalid := abiString("USDT")
+	invalid[uint256Size-1] = 0xff
+	_, ok = decodeABIString(invalid, maxTokenSymbolLength)
+	assert.False(t, ok)
+
+	_, ok = decodeABIDecimals(common.LeftPadBytes([]byte{1, 0}, uint256Size))
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/findoranetwork/rosetta-sdk-go/utils"
+)
+
+// Token is a token contract listed in a token registry file.
+type Token struct {
+	Contract string `json:"contract"`
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+// TokenRegistry holds the tokens known on a network.
+type TokenRegistry struct {
+	byContract map[common.Address]*Token
+	bySymbol   map[string]common.Address
+}
+
+// NewTokenRegistry creates a TokenRegistry from a list of tokens.
+func NewTokenRegistry(tokens []*Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{
+		byContract: map[common.Address]*Token{},
+		bySymbol:   map[string]common.Address{},
+	}
+
+	for _, token := range tokens {
+		contract, ok := ChecksumAddress(token.Contract)
+		if !ok {
+			return nil, fmt.Errorf("invalid token contract address %s", token.Contract)
+		}
+
+		if len(token.Symbol) == 0 {
+			return nil, fmt.Errorf("token %s has no symbol", contract)
+		}
+
+		if token.Decimals < 0 {
+			return nil, fmt.Errorf("token %s has negative decimals", contract)
+		}
+
+		address := common.HexToAddress(contract)
+		if _, ok := r.byContract[address]; ok {
+			return nil, fmt.Errorf("token %s is listed more than once", contract)
+		}
+
+		// Symbols resolve currencies without a contract address, so
+		// they must be unique within a network.
+		if other, ok := r.bySymbol[token.Symbol]; ok {
+			return nil, fmt.Errorf(
+				"tokens %s and %s share the symbol %s",
+				other.Hex(),
+				contract,
+				token.Symbol,
+			)
+		}
+
+		r.byContract[address] = token
+		r.bySymbol[token.Symbol] = address
+	}
+
+	return r, nil
+}
+
+// LoadTokenRegistry loads the tokens of network from a JSON file
+// mapping network names to their list of tokens, for example:
+//
+//	{"Mainnet": [{"contract": "0x...", "symbol": "USDT", "decimals": 18}]}
+func LoadTokenRegistry(path string, network string) (*TokenRegistry, error) {
+	var tokens map[string][]*Token
+	if err := utils.LoadAndParse(path, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: could not load token registry file", err)
+	}
+
+	return NewTokenRegistry(tokens[network])
+}
+
+// Token returns the registered token deployed at contract, if any.
+func (r *TokenRegistry) Token(contract common.Address) (*Token, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	token, ok := r.byContract[contract]
+	return token, ok
+}
+
+// Contract returns the contract address of the registered
+// token with the provided symbol, if any.
+func (r *TokenRegistry) Contract(symbol string) (common.Address, bool) {
+	if r == nil {
+		return common.Address{}, false
+	}
+
+	contract, ok := r.bySymbol[symbol]
+	return contract, ok
+}
+
+// IsNativeCurrency returns a boolean indicating if the
+// provided currency is FRA.
+func IsNativeCurrency(currency *RosettaTypes.Currency) bool {
+	return currency.Symbol == Currency.Symbol &&
+		currency.Decimals == Currency.Decimals &&
+		len(currency.Metadata) == 0
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	mocks "github/findoranetwork/findora-rosetta/mocks/ethereum"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoadTokenRegistry(t *testing.T) {
+	registry, err := LoadTokenRegistry("testdata/token_registry.json", PrinetNetwork)
+	assert.NoError(t, err)
+
+	token, ok := registry.Token(testTokenContract)
+	assert.True(t, ok)
+	assert.Equal(t, "USDT", token.Symbol)
+	assert.Equal(t, int32(6), token.Decimals)
+
+	contract, ok := registry.Contract("USDT")
+	assert.True(t, ok)
+	assert.Equal(t, testTokenContract, contract)
+
+	_, ok = registry.Contract("BLAH")
+	assert.False(t, ok)
+
+	// Networks without tokens have an empty registry
+	registry, err = LoadTokenRegistry("testdata/token_registry.json", MainnetNetwork)
+	assert.NoError(t, err)
+	_, ok = registry.Token(testTokenContract)
+	assert.False(t, ok)
+
+	_, err = LoadTokenRegistry("testdata/missing.json", PrinetNetwork)
+	assert.Error(t, err)
+
+	_, err = NewTokenRegistry([]*Token{{Contract: "blah", Symbol: "USDT"}})
+	assert.Error(t, err)
+
+	_, err = NewTokenRegistry([]*Token{
+		{Contract: testTokenContract.Hex(), Symbol: "USDT", Decimals: 6},
+		{Contract: testTokenTo.Hex(), Symbol: "USDT", Decimals: 6},
+	})
+	assert.EqualError(t, err, fmt.Sprintf(
+		"tokens %s and %s share the symbol USDT",
+		testTokenContract.Hex(),
+		MustChecksum(testTokenTo.Hex()),
+	))
+}
+
+func TestTokenCurrency(t *testing.T) {
+	registry, err := NewTokenRegistry([]*Token{
+		{Contract: testTokenContract.Hex(), Symbol: "USDT", Decimals: 6},
+	})
+	assert.NoError(t, err)
+	c := &Client{tokens: registry}
+
+	usdt, err := c.tokenCurrency(context.Background(), testTokenContract)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.Currency{
+		Symbol:   "USDT",
+		Decimals: 6,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: MustChecksum(testTokenContract.Hex()),
+		},
+	}, usdt)
+
+	contract, err := c.tokenContract(usdt)
+	assert.NoError(t, err)
+	assert.Equal(t, testTokenContract, contract)
+
+	contract, err = c.tokenContract(&RosettaTypes.Currency{Symbol: "USDT", Decimals: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, testTokenContract, contract)
+
+	_, err = c.tokenContract(&RosettaTypes.Currency{Symbol: "BLAH", Decimals: 6})
+	assert.True(t, errors.Is(err, ErrCurrencyNotSupported))
+
+	unknown, err := c.tokenCurrency(context.Background(), testTokenTo)
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownTokenSymbol, unknown.Symbol)
+	assert.Equal(t, int32(0), unknown.Decimals)
+
+	assert.True(t, IsNativeCurrency(Currency))
+	assert.False(t, IsNativeCurrency(usdt))
+}
+
+func TestTokenBalance(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	ctx := context.Background()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to": testTokenContract.Hex(),
+			"data": "0x70a08231000000000000000000000000" +
+				"687422eea2cb73b5d3e242ba5456b782919afc85",
+		},
+		"0x2a",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Bytes)
+			*r = common.LeftPadBytes([]byte{0x64}, uint256Size)
+		},
+	).Once()
+
+	balance, err := c.tokenBalance(ctx, testTokenContract, testTokenFrom, "0x2a")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", balance.String())
+
+	mockJSONRPC.AssertExpectations(t)
+}
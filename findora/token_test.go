@@ -0,0 +1,103 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	RosettaTypes "github.com/findoranetwork/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testTokenContract = common.HexToAddress("0x2a0e9d9a4b8b6f2e2c6e0a1a9a4b8b6f2e2c6e0a")
+	testTokenFrom     = common.HexToAddress("0x687422eea2cb73b5d3e242ba5456b782919afc85")
+	testTokenTo       = common.HexToAddress("0xc662a694fdaa5406a8ee2ca2e94890d58ab578d9")
+)
+
+func testTransferLog(from, to common.Address, value int64, index uint) *types.Log {
+	return &types.Log{
+		Address: testTokenContract,
+		Topics: []common.Hash{
+			transferEventTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:  common.LeftPadBytes(big.NewInt(value).Bytes(), uint256Size),
+		Index: index,
+	}
+}
+
+func TestTokenTransferOps(t *testing.T) {
+	c := &Client{}
+	currency := &RosettaTypes.Currency{
+		Symbol:   UnknownTokenSymbol,
+		Decimals: 0,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: MustChecksum(testTokenContract.Hex()),
+		},
+	}
+
+	tx := &loadedTransaction{
+		Receipt: &types.Receipt{
+			Status: types.ReceiptStatusSuccessful,
+			Logs: []*types.Log{
+				testTransferLog(testTokenFrom, testTokenTo, 100, 0),
+				testTransferLog(common.Address{}, testTokenTo, 5, 1), // mint
+				testTransferLog(testTokenFrom, testTokenTo, 0, 2),    // no value
+			},
+		},
+	}
+
+	ops, err := c.tokenTransferOps(context.Background(), tx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 2},
+			Type:                ERC20TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenFrom.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "-100", Currency: currency},
+			Metadata:            map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 3},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 2}},
+			Type:                ERC20TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenTo.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "100", Currency: currency},
+			Metadata:            map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 4},
+			Type:                ERC20TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: MustChecksum(testTokenTo.Hex())},
+			Amount:              &RosettaTypes.Amount{Value: "5", Currency: currency},
+			Metadata:            map[string]interface{}{"log_index": uint(1)},
+		},
+	}, ops)
+
+	// Logs of failed transactions are ignored
+	tx.Receipt.Status = types.ReceiptStatusFailed
+	ops, err = c.tokenTransferOps(context.Background(), tx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 0)
+}
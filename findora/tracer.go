@@ -15,8 +15,10 @@
 package ethereum
 
 import (
+	// embed is required to ship call_tracer.js inside the binary
+	_ "embed"
 	"fmt"
-	"io/ioutil"
+	"time"
 
 	"github.com/ethereum/go-ethereum/eth/tracers"
 )
@@ -24,22 +26,52 @@ import (
 // convert raw eth data from client to rosetta
 
 const (
-	tracerPath = "ethereum/call_tracer.js"
+	// JSTracer traces transactions with the call_tracer.js
+	// tracer embedded in the binary.
+	JSTracer = "js"
+
+	// NativeTracer traces transactions with go-ethereum's
+	// built-in callTracer, which is much faster than the JS
+	// tracer on big blocks.
+	NativeTracer = "native"
+
+	// nativeCallTracer is the name of go-ethereum's built-in
+	// call tracer.
+	nativeCallTracer = "callTracer"
 )
 
 var (
+	// tracerTimeout is the default timeout of a single
+	// debug_* trace request.
 	tracerTimeout = "120s"
+
+	//go:embed call_tracer.js
+	callTracerJS string
 )
 
-func loadTraceConfig() (*tracers.TraceConfig, error) {
-	loadedFile, err := ioutil.ReadFile(tracerPath)
-	if err != nil {
-		return nil, fmt.Errorf("%w: could not load tracer file", err)
+// loadTraceConfig returns the *tracers.TraceConfig for the provided
+// tracer (JSTracer or NativeTracer) and timeout. An empty tracer
+// defaults to JSTracer and an empty timeout defaults to tracerTimeout.
+func loadTraceConfig(tracer string, timeout string) (*tracers.TraceConfig, error) {
+	if len(timeout) == 0 {
+		timeout = tracerTimeout
+	}
+	if _, err := time.ParseDuration(timeout); err != nil {
+		return nil, fmt.Errorf("%w: invalid tracer timeout %s", err, timeout)
+	}
+
+	var loadedTracer string
+	switch tracer {
+	case JSTracer, "":
+		loadedTracer = callTracerJS
+	case NativeTracer:
+		loadedTracer = nativeCallTracer
+	default:
+		return nil, fmt.Errorf("%s is not a valid tracer", tracer)
 	}
 
-	loadedTracer := string(loadedFile)
 	return &tracers.TraceConfig{
-		Timeout: &tracerTimeout,
+		Timeout: &timeout,
 		Tracer:  &loadedTracer,
 	}, nil
 }
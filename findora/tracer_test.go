@@ -0,0 +1,75 @@
+// Copyright 2020 Findora, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTraceConfig(t *testing.T) {
+	loadedFile, err := ioutil.ReadFile("call_tracer.js")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		tracer  string
+		timeout string
+
+		expectedTracer  string
+		expectedTimeout string
+		err             bool
+	}{
+		"defaults": {
+			expectedTracer:  string(loadedFile),
+			expectedTimeout: tracerTimeout,
+		},
+		"js tracer": {
+			tracer:          JSTracer,
+			timeout:         "30s",
+			expectedTracer:  string(loadedFile),
+			expectedTimeout: "30s",
+		},
+		"native tracer": {
+			tracer:          NativeTracer,
+			expectedTracer:  nativeCallTracer,
+			expectedTimeout: tracerTimeout,
+		},
+		"invalid tracer": {
+			tracer: "blah",
+			err:    true,
+		},
+		"invalid timeout": {
+			timeout: "blah",
+			err:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc, err := loadTraceConfig(test.tracer, test.timeout)
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, tc)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedTracer, *tc.Tracer)
+			assert.Equal(t, test.expectedTimeout, *tc.Timeout)
+		})
+	}
+}
@@ -66,6 +66,18 @@ const (
 	// FeeOpType is used to represent fee operations.
 	FeeOpType = "FEE"
 
+	// FeeBurnOpType is used to represent the part
+	// of a fee that is burned.
+	FeeBurnOpType = "FEE_BURN"
+
+	// FeeTipOpType is used to represent the part
+	// of a fee paid to the block proposer.
+	FeeTipOpType = "FEE_TIP"
+
+	// FeeTreasuryOpType is used to represent the part
+	// of a fee paid to a treasury.
+	FeeTreasuryOpType = "FEE_TREASURY"
+
 	// CallOpType is used to represent CALL trace operations.
 	CallOpType = "CALL"
 
@@ -92,6 +104,30 @@ const (
 	// of a transaction.
 	DestructOpType = "DESTRUCT"
 
+	// ERC20TransferOpType is used to represent ERC-20 (FRC-20)
+	// token transfers decoded from Transfer logs.
+	ERC20TransferOpType = "ERC20_TRANSFER"
+
+	// ERC721TransferOpType is used to represent ERC-721
+	// token transfers decoded from Transfer logs.
+	ERC721TransferOpType = "ERC721_TRANSFER"
+
+	// ERC1155TransferOpType is used to represent ERC-1155 token
+	// transfers decoded from TransferSingle and TransferBatch logs.
+	ERC1155TransferOpType = "ERC1155_TRANSFER"
+
+	// UnknownTokenSymbol is the symbol used in the currency
+	// of tokens whose metadata is not known.
+	UnknownTokenSymbol = "UNKNOWN"
+
+	// ContractAddressKey is the currency metadata key
+	// holding the address of a token contract.
+	ContractAddressKey = "contract_address"
+
+	// TokenIDKey is the currency metadata key holding
+	// the id of a non-fungible (ERC-721 / ERC-1155) token.
+	TokenIDKey = "token_id"
+
 	// SuccessStatus is the status of any
 	// Findora operation considered successful.
 	SuccessStatus = "SUCCESS"
@@ -120,6 +156,18 @@ const (
 	// of a transfer.
 	TransferGasLimit = int64(21000) //nolint:gomnd
 
+	// BlockCacheStatsMethod is the /call method returning
+	// the hit and miss counts of the parsed block cache.
+	BlockCacheStatsMethod = "rosetta_blockCacheStats"
+
+	// BlockByTimestampMethod is the /call method returning
+	// the last block produced at or before a timestamp.
+	BlockByTimestampMethod = "rosetta_blockByTimestamp"
+
+	// AccountBalancesMethod is the /call method returning the
+	// balances and the nonces of many accounts at the same block.
+	AccountBalancesMethod = "rosetta_accountBalances"
+
 	// IncludeMempoolCoins does not apply to findora-rosetta as it is not UTXO-based.
 	IncludeMempoolCoins = false
 )
@@ -170,6 +218,11 @@ var (
 		Index: GenesisBlockIndex,
 	}
 
+	// DefaultFeePolicy is the FeePolicy of all Findora networks.
+	// A share of the tips can be paid to a treasury with a
+	// TreasuryFeePolicy wrapping it (see TREASURY_SHARE).
+	DefaultFeePolicy FeePolicy = &FindoraFeePolicy{}
+
 	// Currency is the *types.Currency for all
 	// Findora networks.
 	Currency = &types.Currency{
@@ -182,6 +235,9 @@ var (
 		MinerRewardOpType,
 		UncleRewardOpType,
 		FeeOpType,
+		FeeBurnOpType,
+		FeeTipOpType,
+		FeeTreasuryOpType,
 		CallOpType,
 		CreateOpType,
 		Create2OpType,
@@ -190,6 +246,9 @@ var (
 		DelegateCallOpType,
 		StaticCallOpType,
 		DestructOpType,
+		ERC20TransferOpType,
+		ERC721TransferOpType,
+		ERC1155TransferOpType,
 	}
 
 	// OperationStatuses are all supported operation statuses.
@@ -210,6 +269,9 @@ var (
 		"eth_getTransactionReceipt",
 		"eth_call",
 		"eth_estimateGas",
+		BlockCacheStatsMethod,
+		BlockByTimestampMethod,
+		AccountBalancesMethod,
 	}
 )
 
@@ -20,13 +20,13 @@ type Client struct {
 	mock.Mock
 }
 
-// Balance provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.PartialBlockIdentifier) (*types.AccountBalanceResponse, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// Balance provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.PartialBlockIdentifier, _a3 []*types.Currency) (*types.AccountBalanceResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 *types.AccountBalanceResponse
-	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier) *types.AccountBalanceResponse); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier, []*types.Currency) *types.AccountBalanceResponse); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*types.AccountBalanceResponse)
@@ -34,8 +34,8 @@ func (_m *Client) Balance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(context.Context, *types.AccountIdentifier, *types.PartialBlockIdentifier, []*types.Currency) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -89,13 +89,13 @@ func (_m *Client) Call(ctx context.Context, request *types.CallRequest) (*types.
 	return r0, r1
 }
 
-// GetMempool provides a mock function with given fields: ctx
-func (_m *Client) GetMempool(ctx context.Context) (*types.MempoolResponse, error) {
-	ret := _m.Called(ctx)
+// GetMempool provides a mock function with given fields: ctx, metadata
+func (_m *Client) GetMempool(ctx context.Context, metadata map[string]interface{}) (*types.MempoolResponse, error) {
+	ret := _m.Called(ctx, metadata)
 
 	var r0 *types.MempoolResponse
-	if rf, ok := ret.Get(0).(func(context.Context) *types.MempoolResponse); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]interface{}) *types.MempoolResponse); ok {
+		r0 = rf(ctx, metadata)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*types.MempoolResponse)
@@ -103,8 +103,31 @@ func (_m *Client) GetMempool(ctx context.Context) (*types.MempoolResponse, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, map[string]interface{}) error); ok {
+		r1 = rf(ctx, metadata)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMempoolTransaction provides a mock function with given fields: ctx, transaction
+func (_m *Client) GetMempoolTransaction(ctx context.Context, transaction *types.TransactionIdentifier) (*types.MempoolTransactionResponse, error) {
+	ret := _m.Called(ctx, transaction)
+
+	var r0 *types.MempoolTransactionResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *types.TransactionIdentifier) *types.MempoolTransactionResponse); ok {
+		r0 = rf(ctx, transaction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.MempoolTransactionResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.TransactionIdentifier) error); ok {
+		r1 = rf(ctx, transaction)
 	} else {
 		r1 = ret.Error(1)
 	}
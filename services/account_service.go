@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github/findoranetwork/findora-rosetta/configuration"
+	findora "github/findoranetwork/findora-rosetta/findora"
 
 	"github.com/findoranetwork/rosetta-sdk-go/types"
 )
@@ -52,7 +54,19 @@ func (s *AccountAPIService) AccountBalance(
 		ctx,
 		request.AccountIdentifier,
 		request.BlockIdentifier,
+		request.Currencies,
 	)
+	if errors.Is(err, findora.ErrCurrencyNotSupported) ||
+		errors.Is(err, findora.ErrInvalidAddress) ||
+		errors.Is(err, findora.ErrInvalidBlockHash) ||
+		errors.Is(err, findora.ErrInvalidContractAddress) ||
+		errors.Is(err, findora.ErrInvalidBalanceOptions) ||
+		errors.Is(err, findora.ErrInvalidTimestamp) {
+		return nil, wrapErr(ErrInvalidInput, err)
+	}
+	if errors.Is(err, findora.ErrBlockOrphaned) {
+		return nil, wrapErr(ErrBlockOrphaned, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrFindora, err)
 	}
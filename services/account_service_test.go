@@ -16,6 +16,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github/findoranetwork/findora-rosetta/configuration"
@@ -79,6 +80,7 @@ func TestAccountBalance_Online(t *testing.T) {
 		ctx,
 		account,
 		types.ConstructPartialBlockIdentifier(block),
+		[]*types.Currency(nil),
 	).Return(resp, nil).Once()
 
 	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
@@ -95,3 +97,143 @@ func TestAccountBalance_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestAccountBalance_Currencies(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	token := &types.Currency{
+		Symbol:   "USDT",
+		Decimals: 18,
+		Metadata: map[string]interface{}{
+			findora.ContractAddressKey: "0x2A0E9D9a4b8b6F2E2c6e0A1A9a4b8B6F2e2C6e0a",
+		},
+	}
+
+	resp := &types.AccountBalanceResponse{
+		BlockIdentifier: &types.BlockIdentifier{
+			Index: 1000,
+			Hash:  "block 1000",
+		},
+		Balances: []*types.Amount{
+			{
+				Value:    "25",
+				Currency: findora.Currency,
+			},
+			{
+				Value:    "10",
+				Currency: token,
+			},
+		},
+	}
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		(*types.PartialBlockIdentifier)(nil),
+		[]*types.Currency{findora.Currency, token},
+	).Return(resp, nil).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+		Currencies:        []*types.Currency{findora.Currency, token},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, resp, bal)
+
+	unknown := &types.Currency{Symbol: "BLAH", Decimals: 18}
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		(*types.PartialBlockIdentifier)(nil),
+		[]*types.Currency{unknown},
+	).Return(nil, findora.ErrCurrencyNotSupported).Once()
+
+	bal, err = servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+		Currencies:        []*types.Currency{unknown},
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAccountBalance_InvalidOptions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+		Metadata: map[string]interface{}{
+			"include_proof": "yes",
+		},
+	}
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		(*types.PartialBlockIdentifier)(nil),
+		[]*types.Currency(nil),
+	).Return(nil, findora.ErrInvalidBalanceOptions).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrInvalidInput.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAccountBalance_Orphaned(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+
+	hash := "block 1000"
+	block := &types.PartialBlockIdentifier{Hash: &hash}
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		block,
+		[]*types.Currency(nil),
+	).Return(nil, fmt.Errorf("%w: block 1000", findora.ErrBlockOrphaned)).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+		BlockIdentifier:   block,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrBlockOrphaned.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	mockClient.AssertExpectations(t)
+}
@@ -48,7 +48,9 @@ func (s *CallAPIService) Call(
 	}
 
 	response, err := s.client.Call(ctx, request)
-	if errors.Is(err, findora.ErrCallParametersInvalid) {
+	if errors.Is(err, findora.ErrCallParametersInvalid) ||
+		errors.Is(err, findora.ErrInvalidTimestamp) ||
+		errors.Is(err, findora.ErrInvalidBalanceOptions) {
 		return nil, wrapErr(ErrCallParametersInvalid, err)
 	}
 	if errors.Is(err, findora.ErrCallOutputMarshal) {
@@ -57,6 +59,9 @@ func (s *CallAPIService) Call(
 	if errors.Is(err, findora.ErrCallMethodInvalid) {
 		return nil, wrapErr(ErrCallMethodInvalid, err)
 	}
+	if errors.Is(err, findora.ErrBlockOrphaned) {
+		return nil, wrapErr(ErrBlockOrphaned, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrFindora, err)
 	}
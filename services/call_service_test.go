@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github/findoranetwork/findora-rosetta/configuration"
+	findora "github/findoranetwork/findora-rosetta/findora"
 	mocks "github/findoranetwork/findora-rosetta/mocks/services"
 
 	"github.com/findoranetwork/rosetta-sdk-go/types"
@@ -65,3 +66,49 @@ func TestCall_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestCall_Orphaned(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	request := &types.CallRequest{
+		Method: findora.AccountBalancesMethod,
+	}
+
+	mockClient.On("Call", ctx, request).Return(nil, findora.ErrBlockOrphaned).Once()
+	callResp, err := servicer.Call(ctx, request)
+	assert.Nil(t, callResp)
+	assert.Equal(t, ErrBlockOrphaned.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestCall_InvalidParameters(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewCallAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	request := &types.CallRequest{
+		Method: findora.BlockByTimestampMethod,
+	}
+
+	for _, clientErr := range []error{
+		findora.ErrInvalidTimestamp,
+		findora.ErrInvalidBalanceOptions,
+	} {
+		mockClient.On("Call", ctx, request).Return(nil, clientErr).Once()
+		callResp, err := servicer.Call(ctx, request)
+		assert.Nil(t, callResp)
+		assert.Equal(t, ErrCallParametersInvalid.Code, err.Code)
+	}
+
+	mockClient.AssertExpectations(t)
+}
@@ -55,10 +55,14 @@ func TestConstructionService(t *testing.T) {
 		Blockchain: findora.Blockchain,
 	}
 
+	// The signed fixtures below were produced for chain ID 3.
+	params := *findora.AnvilChainConfig
+	params.ChainID = big.NewInt(3)
+
 	cfg := &configuration.Configuration{
 		Mode:    configuration.Online,
 		Network: networkIdentifier,
-		Params:  findora.AnvilChainConfig,
+		Params:  &params,
 	}
 
 	mockClient := &mocks.Client{}
@@ -89,7 +93,7 @@ func TestConstructionService(t *testing.T) {
 	// Test Preprocess
 	// curl -H 'Content-Type: application/json' --data '{"network_identifier":{"blockchain":"Findora","network":"Prinet"},"operations":[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0xa46Ff39c35da34E91f308389b64f43226B579327"},"amount":{"value":"-42894881044106498","currency":{"symbol":"FRA","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"FRA","decimals":18}}}]}' http://127.0.0.1:8080/construction/preprocess
 
-	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0xe3a5B4d7f79d64088C8d4ef153A7DDe2B2d47309"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	intent := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0xe3a5B4d7f79d64088C8d4ef153A7DDe2B2d47309"},"amount":{"value":"-42894881044106498","currency":{"symbol":"FRA","decimals":18}}},{"operation_identifier":{"index":1},"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"FRA","decimals":18}}}]` // nolint
 	var ops []*types.Operation
 	assert.NoError(t, json.Unmarshal([]byte(intent), &ops))
 	preprocessResponse, err := servicer.ConstructionPreprocess(
@@ -166,7 +170,7 @@ func TestConstructionService(t *testing.T) {
 	// Test Parse Unsigned
 	// curl -H 'Content-Type: application/json' --data '{"network_identifier":{"blockchain":"Findora","network":"Prinet"},"signed":true,"transaction":"{\"type\":\"0x0\",\"nonce\":\"0x2\",\"gasPrice\":\"0x2540be400\",\"maxPriorityFeePerGas\":null,\"maxFeePerGas\":null,\"gas\":\"0x5208\",\"value\":\"0x9864aac3510d02\",\"input\":\"0x\",\"v\":\"0x10f4\",\"r\":\"0x8c712c64bc65c4a88707fa93ecd090144dffb1bf133805a10a51d354c2f9f2b2\",\"s\":\"0x5a63cea6989f4c58372c41f31164036a6b25dce1d5c05e1d31c16c0590c176e8\",\"to\":\"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d\",\"hash\":\"0x3125474d60f757a35a9b98ca0e3817d62d0d8720a45f3a74ef84e6aa892bcc2a\"}"}' http://127.0.0.1:8080/construction/parse
 
-	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0xe3a5B4d7f79d64088C8d4ef153A7DDe2B2d47309"},"amount":{"value":"-42894881044106498","currency":{"symbol":"ETH","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"ETH","decimals":18}}}]` // nolint
+	parseOpsRaw := `[{"operation_identifier":{"index":0},"type":"CALL","account":{"address":"0xe3a5B4d7f79d64088C8d4ef153A7DDe2B2d47309"},"amount":{"value":"-42894881044106498","currency":{"symbol":"FRA","decimals":18}}},{"operation_identifier":{"index":1},"related_operations":[{"index":0}],"type":"CALL","account":{"address":"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},"amount":{"value":"42894881044106498","currency":{"symbol":"FRA","decimals":18}}}]` // nolint
 	var parseOps []*types.Operation
 	assert.NoError(t, json.Unmarshal([]byte(parseOpsRaw), &parseOps))
 	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
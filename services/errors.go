@@ -37,6 +37,7 @@ var (
 		ErrInvalidAddress,
 		ErrFindoraNotReady,
 		ErrInvalidInput,
+		ErrTransactionNotFound,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -152,6 +153,13 @@ var (
 		Code:    14, //nolint
 		Message: "invalid input",
 	}
+
+	// ErrTransactionNotFound is returned when a
+	// transaction is not in the mempool
+	ErrTransactionNotFound = &types.Error{
+		Code:    15, //nolint
+		Message: "Transaction not found",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github/findoranetwork/findora-rosetta/configuration"
+	findora "github/findoranetwork/findora-rosetta/findora"
 
 	"github.com/findoranetwork/rosetta-sdk-go/server"
 	"github.com/findoranetwork/rosetta-sdk-go/types"
@@ -43,13 +45,16 @@ func NewMempoolAPIService(
 // Mempool implements the /mempool endpoint.
 func (s *MempoolAPIService) Mempool(
 	ctx context.Context,
-	_ *types.NetworkRequest,
+	request *types.NetworkRequest,
 ) (*types.MempoolResponse, *types.Error) {
 	if s.config.Mode != configuration.Online {
 		return nil, ErrUnavailableOffline
 	}
 
-	response, err := s.client.GetMempool(ctx)
+	response, err := s.client.GetMempool(ctx, request.Metadata)
+	if errors.Is(err, findora.ErrInvalidMempoolFilter) {
+		return nil, wrapErr(ErrInvalidInput, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrFindora, err)
 	}
@@ -62,5 +67,17 @@ func (s *MempoolAPIService) MempoolTransaction(
 	ctx context.Context,
 	request *types.MempoolTransactionRequest,
 ) (*types.MempoolTransactionResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	response, err := s.client.GetMempoolTransaction(ctx, request.TransactionIdentifier)
+	if errors.Is(err, findora.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrFindora, err)
+	}
+
+	return response, nil
 }
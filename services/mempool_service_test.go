@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github/findoranetwork/findora-rosetta/configuration"
+	findora "github/findoranetwork/findora-rosetta/findora"
 	mocks "github/findoranetwork/findora-rosetta/mocks/services"
 
 	"github.com/findoranetwork/rosetta-sdk-go/types"
@@ -41,8 +42,8 @@ func TestMempoolService_Offline(t *testing.T) {
 
 	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
 	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 
 	mockClient.AssertExpectations(t)
 }
@@ -65,7 +66,7 @@ func TestMempoolService_Online(t *testing.T) {
 
 	t.Run("mempool", func(t *testing.T) {
 		mockClient.
-			On("GetMempool", ctx).
+			On("GetMempool", ctx, map[string]interface{}(nil)).
 			Return(mempool, nil).
 			Once()
 
@@ -75,5 +76,61 @@ func TestMempoolService_Online(t *testing.T) {
 		assert.Equal(t, mempool, actualMempool)
 	})
 
+	t.Run("mempool invalid filter", func(t *testing.T) {
+		metadata := map[string]interface{}{
+			"pool": "future",
+		}
+
+		mockClient.
+			On("GetMempool", ctx, metadata).
+			Return(nil, findora.ErrInvalidMempoolFilter).
+			Once()
+
+		actualMempool, err := servicer.Mempool(ctx, &types.NetworkRequest{Metadata: metadata})
+
+		assert.Nil(t, actualMempool)
+		assert.Equal(t, ErrInvalidInput.Code, err.Code)
+	})
+
+	t.Run("mempool transaction", func(t *testing.T) {
+		request := &types.MempoolTransactionRequest{
+			TransactionIdentifier: mempool.TransactionIdentifiers[0],
+		}
+		response := &types.MempoolTransactionResponse{
+			Transaction: &types.Transaction{
+				TransactionIdentifier: mempool.TransactionIdentifiers[0],
+				Metadata: map[string]interface{}{
+					"pool": "pending",
+				},
+			},
+		}
+
+		mockClient.
+			On("GetMempoolTransaction", ctx, request.TransactionIdentifier).
+			Return(response, nil).
+			Once()
+
+		actualResponse, err := servicer.MempoolTransaction(ctx, request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, response, actualResponse)
+	})
+
+	t.Run("mempool transaction not found", func(t *testing.T) {
+		request := &types.MempoolTransactionRequest{
+			TransactionIdentifier: mempool.TransactionIdentifiers[0],
+		}
+
+		mockClient.
+			On("GetMempoolTransaction", ctx, request.TransactionIdentifier).
+			Return(nil, findora.ErrTransactionNotFound).
+			Once()
+
+		actualResponse, err := servicer.MempoolTransaction(ctx, request)
+
+		assert.Nil(t, actualResponse)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+	})
+
 	mockClient.AssertExpectations(t)
 }
@@ -51,6 +51,7 @@ type Client interface {
 		context.Context,
 		*types.AccountIdentifier,
 		*types.PartialBlockIdentifier,
+		[]*types.Currency,
 	) (*types.AccountBalanceResponse, error)
 
 	PendingNonceAt(context.Context, common.Address) (uint64, error)
@@ -59,7 +60,15 @@ type Client interface {
 
 	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
 
-	GetMempool(ctx context.Context) (*types.MempoolResponse, error)
+	GetMempool(
+		ctx context.Context,
+		metadata map[string]interface{},
+	) (*types.MempoolResponse, error)
+
+	GetMempoolTransaction(
+		ctx context.Context,
+		transaction *types.TransactionIdentifier,
+	) (*types.MempoolTransactionResponse, error)
 
 	Call(
 		ctx context.Context,